@@ -0,0 +1,36 @@
+package diff
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Parallel diffs
+
+// A Pair holds two sets of lines to be diffed.
+type Pair struct {
+	A, B []string
+}
+
+// DiffAll computes SideBySideResult for each pair, running up to
+// runtime.NumCPU() diffs at a time. The results are in the order of pairs.
+func DiffAll(pairs []Pair) []Result {
+	results := make([]Result, len(pairs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := min(runtime.NumCPU(), len(pairs)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range next {
+				results[k] = SideBySideResult(pairs[k].A, pairs[k].B)
+			}
+		}()
+	}
+	for k := range pairs {
+		next <- k
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,27 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffAll(t *testing.T) {
+	if have := DiffAll(nil); len(have) != 0 {
+		t.Errorf("DiffAll(nil) = %v", have)
+	}
+	r := rand.New(rand.NewSource(1))
+	pairs := make([]Pair, 100)
+	for k := range pairs {
+		pairs[k] = Pair{
+			strings.Split(randString(r, 20, "abc"), ""),
+			strings.Split(randString(r, 20, "abc"), ""),
+		}
+	}
+	for k, have := range DiffAll(pairs) {
+		if want := SideBySideResult(pairs[k].A, pairs[k].B); !reflect.DeepEqual(have, want) {
+			t.Errorf("pair %d:\nwant %v\nhave %v\n", k, want, have)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package diff
+
+import "fmt"
+
+// Anchored diff
+
+// DiffAnchored is like Diff but aligns left[i] with right[j] for each anchor
+// {i, j}, diffing the parts between consecutive anchors independently. The
+// anchors must be in range, sorted by both i and j, and name equal elements;
+// DiffAnchored panics otherwise.
+func DiffAnchored(data Interface, anchors [][2]int) int {
+	edits, err := DiffAnchoredSafe(data, anchors)
+	if err != nil {
+		panic(err)
+	}
+	return edits
+}
+
+// DiffAnchoredSafe is like DiffAnchored but returns an error instead of
+// panicking, as DiffSafe does. Invalid anchors are reported before
+// data.Common is called.
+func DiffAnchoredSafe(data Interface, anchors [][2]int) (edits int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			edits, err = 0, panicError(r)
+		}
+	}()
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		return 0, err
+	}
+	for k, a := range anchors {
+		i, j := a[0], a[1]
+		switch {
+		case i < 0 || i >= n || j < 0 || j >= m:
+			return 0, fmt.Errorf("diff: anchor %d at (%d, %d) is out of range", k, i, j)
+		case k > 0 && (i <= anchors[k-1][0] || j <= anchors[k-1][1]):
+			return 0, fmt.Errorf("diff: anchor %d at (%d, %d) does not follow anchor %d", k, i, j, k-1)
+		case !data.Equal(i, j):
+			return 0, fmt.Errorf("diff: anchor %d at (%d, %d) aligns different elements", k, i, j)
+		}
+	}
+	r := &runs{data: data}
+	i0, j0 := 0, 0
+	for k := 0; k <= len(anchors); k++ {
+		i1, j1 := n, m
+		if k < len(anchors) {
+			i1, j1 = anchors[k][0], anchors[k][1]
+		}
+		e, err := DiffSafe(&window{data, r, i0, i1, j0, j1})
+		if err != nil {
+			return 0, err
+		}
+		edits += e
+		if k < len(anchors) {
+			r.add(i1, j1, 1)
+			i0, j0 = i1+1, j1+1
+		}
+	}
+	r.flush(n, m)
+	return edits, nil
+}
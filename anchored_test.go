@@ -0,0 +1,53 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffAnchored(t *testing.T) {
+	var tests = []struct {
+		a, b    string
+		anchors [][2]int
+		common  [][3]int
+	}{
+		{"abc", "abc", nil, [][3]int{{0, 0, 3}}},
+		{"abc", "abc", [][2]int{{0, 0}, {2, 2}}, [][3]int{{0, 0, 3}}},
+		// Without the anchor, the "h" of each section would be aligned
+		// with the "h" of the other.
+		{"hxhy", "hyhx", nil, [][3]int{{0, 0, 1}, {3, 1, 1}, {4, 4, 0}}},
+		{"hxhy", "hyhx", [][2]int{{0, 0}, {2, 2}}, [][3]int{{0, 0, 1}, {2, 2, 1}, {4, 4, 0}}},
+		{"ab", "ba", [][2]int{{1, 0}}, [][3]int{{1, 0, 1}, {2, 2, 0}}},
+	}
+	for i, test := range tests {
+		d := &lineDiff{a: strings.Split(test.a, ""), b: strings.Split(test.b, "")}
+		edits := DiffAnchored(d, test.anchors)
+		if !reflect.DeepEqual(d.common, test.common) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.common, d.common)
+		}
+		checkCommon(t, d, edits)
+	}
+}
+
+func TestDiffAnchoredSafe(t *testing.T) {
+	var tests = []struct {
+		anchors [][2]int
+		err     string
+	}{
+		{[][2]int{{0, 3}}, "diff: anchor 0 at (0, 3) is out of range"},
+		{[][2]int{{-1, 0}}, "diff: anchor 0 at (-1, 0) is out of range"},
+		{[][2]int{{1, 1}, {1, 2}}, "diff: anchor 1 at (1, 2) does not follow anchor 0"},
+		{[][2]int{{1, 1}, {0, 2}}, "diff: anchor 1 at (0, 2) does not follow anchor 0"},
+		{[][2]int{{0, 1}}, "diff: anchor 0 at (0, 1) aligns different elements"},
+	}
+	for i, test := range tests {
+		d := &lineDiff{a: strings.Split("abc", ""), b: strings.Split("abc", "")}
+		if _, err := DiffAnchoredSafe(d, test.anchors); err == nil || err.Error() != test.err {
+			t.Errorf("test %d: want error %q, have %v", i, test.err, err)
+		}
+		if d.common != nil {
+			t.Errorf("test %d: Common called: %v", i, d.common)
+		}
+	}
+}
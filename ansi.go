@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Terminal output
+
+// ANSI escape sequences for the colors of lines.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// ANSI renders side-by-side diffs in the style of a unified diff for a
+// terminal, with deleted lines in red and added lines in green.
+type ANSI struct {
+	NoColor bool // Do not write escape sequences, for output that is not a terminal.
+}
+
+// RenderANSI renders lines to w with the zero ANSI.
+func RenderANSI(lines []SideBySideLine, w io.Writer) error {
+	return ANSI{}.Render(lines, w)
+}
+
+// Render writes lines to w. Unchanged lines, including NearMatch lines, are
+// prefixed with " ". Within each run of changes, the left sides are written
+// first, prefixed with "-", followed by the right sides, prefixed with "+".
+// The old and new places of moved lines are written in magenta and cyan, and
+// each Skipped line as "@@ n unchanged lines @@". Colors are reset at the end
+// of each line. Lines of other types are left out.
+func (a ANSI) Render(lines []SideBySideLine, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for r := 0; r < len(lines); {
+		switch l := lines[r]; l.Type {
+		case NoChange, NearMatch:
+			a.line(bw, "", " ", l.Left)
+			r++
+		case Skipped:
+			a.line(bw, ansiCyan, "", fmt.Sprintf("@@ %d unchanged lines @@", l.Count))
+			r++
+		case Moved:
+			end := r
+			old := false // the run is the old place of the lines
+			for end < len(lines) && lines[end].Type == Moved && lines[end].MoveID == l.MoveID {
+				old = old || lines[end].Left != ""
+				end++
+			}
+			for _, l := range lines[r:end] {
+				if old {
+					a.line(bw, ansiMagenta, "-", l.Left)
+				} else {
+					a.line(bw, ansiCyan, "+", l.Right)
+				}
+			}
+			r = end
+		case Added, Deleted, Changed:
+			end := r
+			for end < len(lines) && (lines[end].Type == Added || lines[end].Type == Deleted || lines[end].Type == Changed) {
+				end++
+			}
+			for _, l := range lines[r:end] {
+				if l.Type != Added {
+					a.line(bw, ansiRed, "-", l.Left)
+				}
+			}
+			for _, l := range lines[r:end] {
+				if l.Type != Deleted {
+					a.line(bw, ansiGreen, "+", l.Right)
+				}
+			}
+			r = end
+		default:
+			r++
+		}
+	}
+	return bw.Flush()
+}
+
+// line writes a line of text with a prefix in color.
+func (a ANSI) line(w *bufio.Writer, color, prefix, text string) {
+	if a.NoColor || color == "" {
+		w.WriteString(prefix + text + "\n")
+		return
+	}
+	w.WriteString(color + prefix + text + ansiReset + "\n")
+}
+
+// columnMarkers are the markers RenderColumns writes for the line types.
+var columnMarkers = []byte{
+	NoChange:  ' ',
+	Added:     '+',
+	Deleted:   '-',
+	Changed:   '~',
+	Skipped:   ' ',
+	Moved:     '>',
+	NearMatch: ' ',
+}
+
+// RenderColumns writes lines to w as two columns of plain text, each line as
+// its Left text padded to leftWidth runes, " | ", a marker for its Type and,
+// if Right is not empty, a space and Right. A Left text longer than leftWidth
+// is cut to leftWidth runes, the last of which is replaced by "…". The
+// markers are "+" for Added, "-" for Deleted, "~" for Changed, ">" for Moved
+// and " " for unchanged lines. A Skipped line is written as
+// "@@ n unchanged lines @@" in the left column. Lines of other types are left
+// out.
+func RenderColumns(lines []SideBySideLine, w io.Writer, leftWidth int) error {
+	leftWidth = max(leftWidth, 0)
+	bw := bufio.NewWriter(w)
+	for _, l := range lines {
+		if l.Type < 0 || int(l.Type) >= len(columnMarkers) {
+			continue
+		}
+		left := l.Left
+		if l.Type == Skipped {
+			left = fmt.Sprintf("@@ %d unchanged lines @@", l.Count)
+		}
+		if n := utf8.RuneCountInString(left); n > leftWidth {
+			left = truncate(left, leftWidth)
+		} else {
+			left += strings.Repeat(" ", leftWidth-n)
+		}
+		bw.WriteString(left + " | " + string(columnMarkers[l.Type]))
+		if l.Right != "" {
+			bw.WriteString(" " + l.Right)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// truncate cuts s to width runes, the last of which is "…".
+func truncate(s string, width int) string {
+	if width == 0 {
+		return ""
+	}
+	i := 0
+	for k := 0; k < width-1; k++ {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return s[:i] + "…"
+}
@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderANSI(t *testing.T) {
+	lines := []SideBySideLine{
+		{Left: "a", Right: "a", Type: NoChange},
+		{Left: "b", Right: "x", Type: Changed},
+		{Right: "y", Type: Added},
+		{Type: Skipped, Count: 3},
+		{Left: "m", Type: Moved, MoveID: 1},
+		{Type: Moved, MoveID: 1},
+		{Left: "c", Type: Deleted},
+		{Right: "m", Type: Moved, MoveID: 1},
+		{Type: Moved, MoveID: 1},
+	}
+	var w strings.Builder
+	if err := RenderANSI(lines, &w); err != nil {
+		t.Fatal(err)
+	}
+	want := " a\n" +
+		"\x1b[31m-b\x1b[0m\n\x1b[32m+x\x1b[0m\n\x1b[32m+y\x1b[0m\n" +
+		"\x1b[36m@@ 3 unchanged lines @@\x1b[0m\n" +
+		"\x1b[35m-m\x1b[0m\n\x1b[35m-\x1b[0m\n" +
+		"\x1b[31m-c\x1b[0m\n" +
+		"\x1b[36m+m\x1b[0m\n\x1b[36m+\x1b[0m\n"
+	if have := w.String(); have != want {
+		t.Errorf("want %q\nhave %q", want, have)
+	}
+
+	w.Reset()
+	if err := (ANSI{NoColor: true}).Render(lines, &w); err != nil {
+		t.Fatal(err)
+	}
+	want = " a\n-b\n+x\n+y\n@@ 3 unchanged lines @@\n-m\n-\n-c\n+m\n+\n"
+	if have := w.String(); have != want {
+		t.Errorf("no color: want %q\nhave %q", want, have)
+	}
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestRenderANSIError(t *testing.T) {
+	broken := errors.New("broken")
+	if err := RenderANSI(SideBySide([]string{"a"}, []string{"b"}), errWriter{broken}); err != broken {
+		t.Errorf("want %v, have %v", broken, err)
+	}
+}
+
+func TestRenderColumns(t *testing.T) {
+	lines := []SideBySideLine{
+		{Left: "a", Right: "a", Type: NoChange},
+		{Left: "b", Right: "x", Type: Changed},
+		{Right: "y", Type: Added},
+		{Left: "c", Type: Deleted},
+		{Type: Skipped, Count: 3},
+		{Left: "a long line", Right: "a long line", Type: NoChange},
+		{Left: "ünïcödé", Right: "ü", Type: Changed},
+	}
+	var w strings.Builder
+	if err := RenderColumns(lines, &w, 6); err != nil {
+		t.Fatal(err)
+	}
+	want := "a      |   a\n" +
+		"b      | ~ x\n" +
+		"       | + y\n" +
+		"c      | -\n" +
+		"@@ 3 … |  \n" +
+		"a lon… |   a long line\n" +
+		"ünïcö… | ~ ü\n"
+	if w.String() != want {
+		t.Errorf("want %q\nhave %q", want, w.String())
+	}
+}
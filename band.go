@@ -0,0 +1,64 @@
+package diff
+
+// Banded diff
+
+// DiffBand returns the length of the edit script Diff returns for data, but
+// only searches the diagonals of the edit graph within band of the main one,
+// that is, alignments of element i of the left with element j of the right
+// sequence for which |i-j| <= band. This is much faster than Diff for long
+// sequences with local changes. The search gives up, returning 0 and false,
+// as soon as it is clear that the shortest path might leave the band; this is
+// always the case if the lengths n and m of the sequences differ by more than
+// band, and never if the edit distance is at most band. Otherwise it returns
+// the edit distance and true. data.Common is not called.
+func DiffBand(data Interface, band int) (int, bool) {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	delta := n - m
+	if band < 0 || delta > band || -delta > band {
+		return 0, false
+	}
+	// A path that leaves the band for diagonal band+1 or -band-1 and comes
+	// back to diagonal delta, on which (n, m) lies, takes at least limit+1
+	// edits, so a path found within limit edits is a shortest one.
+	limit := 2*band + 1 - max(delta, -delta)
+
+	// v[k+band+1] is the furthest x reached on diagonal k with the current
+	// or the previous number of edits, depending on the parity of k, or -1
+	// if that diagonal has not been reached.
+	v := make([]int, 2*band+3)
+	for k := range v {
+		v[k] = -1
+	}
+	at := func(k int) int { return k + band + 1 }
+	for d := 0; d <= limit; d++ {
+		lo := max(-d, -band)
+		if (lo+d)%2 != 0 {
+			lo++ // only every other diagonal can be reached with d edits
+		}
+		for k := lo; k <= min(d, band); k += 2 {
+			x := -1
+			if d == 0 {
+				x = 0
+			}
+			if down := v[at(k+1)]; k < band && down >= 0 && down-k <= m {
+				x = down
+			}
+			if right := v[at(k-1)] + 1; k > -band && right > 0 && right <= n && right > x {
+				x = right
+			}
+			if x >= 0 {
+				for x < n && x-k < m && data.Equal(x, x-k) {
+					x++
+				}
+				if x == n && x-k == m {
+					return d, true
+				}
+			}
+			v[at(k)] = x
+		}
+	}
+	return 0, false
+}
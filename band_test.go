@@ -0,0 +1,28 @@
+package diff
+
+import "testing"
+
+func TestDiffBand(t *testing.T) {
+	for i, test := range diffTests {
+		for band := 0; band <= 8; band++ {
+			edits, ok := DiffBand(&stringDiff{a: test.a, b: test.b}, band)
+			if ok && edits != test.edits {
+				t.Errorf("test %d band %d: want %d edits, have %d", i, band, test.edits, edits)
+			}
+			if !ok && test.edits <= band {
+				t.Errorf("test %d band %d: no path found for %d edits", i, band, test.edits)
+			}
+		}
+	}
+
+	// A long sequence with a few local changes.
+	a := numbers(10000, nil)
+	b := numbers(10000, map[int]string{10: "x", 5000: "y", 5001: "z"})
+	b = append(b[:7000], b[7001:]...)
+	if edits, ok := DiffBand(lineData{a, b}, 4); !ok || edits != 7 {
+		t.Errorf("want 7 edits, have %d, %v", edits, ok)
+	}
+	if _, ok := DiffBand(lineData{a, b[:9990]}, 4); ok {
+		t.Error("lengths differing by more than the band: want no path")
+	}
+}
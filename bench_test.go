@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// benchLines returns two versions of an n-line file, the second with every
+// step-th line changed.
+func benchLines(n, step int) ([]string, []string) {
+	a := numbers(n, nil)
+	subst := map[int]string{}
+	for i := step; i <= n; i += step {
+		subst[i] = "changed"
+	}
+	return a, numbers(n, subst)
+}
+
+func benchmarkLines(b *testing.B, x, y []string) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Diff(lineData{x, y})
+	}
+}
+
+func BenchmarkDiffSmall(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, test := range diffTests {
+			Diff(&stringDiff{a: test.a, b: test.b})
+		}
+	}
+}
+
+func BenchmarkDiffMedium(b *testing.B) {
+	x, y := benchLines(2000, 50)
+	benchmarkLines(b, x, y)
+}
+
+func BenchmarkDiffLarge(b *testing.B) {
+	x, y := benchLines(50000, 1000)
+	benchmarkLines(b, x, y)
+}
+
+func BenchmarkDiffDisjoint(b *testing.B) {
+	x, y := benchLines(1000, 1)
+	benchmarkLines(b, x, y)
+}
+
+func BenchmarkDiffBytes(b *testing.B) {
+	x := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog\n"), 100)
+	y := bytes.ReplaceAll(x, []byte("fox"), []byte("cat"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DiffBytes(x, y)
+	}
+}
+
+func BenchmarkDiffMiddle(b *testing.B) {
+	x, y := benchLines(50000, 25000)
+	benchmarkLines(b, x, y)
+}
+
+func BenchmarkDiffInts(b *testing.B) {
+	x, y := benchLines(50000, 1000)
+	ids := map[string]int{}
+	intern := func(lines []string) []int {
+		s := make([]int, len(lines))
+		for k, l := range lines {
+			id, ok := ids[l]
+			if !ok {
+				id = len(ids)
+				ids[l] = id
+			}
+			s[k] = id
+		}
+		return s
+	}
+	ix, iy := intern(x), intern(y)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DiffInts(ix, iy)
+	}
+}
+
+// BenchmarkDiffRepetitive diffs two versions of a file of identical lines,
+// one with a line changed in the middle.
+func BenchmarkDiffRepetitive(b *testing.B) {
+	x := make([]string, 50000)
+	for i := range x {
+		x[i] = "same"
+	}
+	y := append([]string(nil), x...)
+	y[len(y)/2] = "changed"
+	benchmarkLines(b, x, y)
+}
+
+// BenchmarkDiffUnbalanced diffs a short file against a long one with nothing
+// in common, for which most diagonals leave the edit graph.
+func BenchmarkDiffUnbalanced(b *testing.B) {
+	x, y := numbers(10, nil), numbers(5000, nil)
+	for i := range x {
+		x[i] = "x"
+	}
+	benchmarkLines(b, x, y)
+}
+
+// BenchmarkDiffEqualCalls reports the calls to Equal that Diff makes, and
+// how many of them are not made by the forward search alone, which is none
+// as the path is traced back from the furthest points the search reached.
+func BenchmarkDiffEqualCalls(b *testing.B) {
+	x, y := benchLines(2000, 50)
+	var calls, search int
+	for i := 0; i < b.N; i++ {
+		_, calls = DiffMetered(lineData{x, y})
+		m := &meter{Interface: lineData{x, y}, max: math.MaxInt}
+		DiffDistance(m)
+		search = m.calls
+	}
+	b.ReportMetric(float64(calls), "equal/op")
+	b.ReportMetric(float64(calls-search), "backtrace-equal/op")
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Chunked diff
+
+// DiffChunked computes a side-by-side diff of the lines read from a and b, as
+// split by ReadLines without their "\n", and passes the lines of the diff to
+// emit one by one, holding only about chunkLines lines of each reader at a
+// time. It reads up to chunkLines lines of each file, diffs them, emits the
+// lines of the diff up to its last unchanged line, and carries the rest over
+// to be diffed with the next lines read. Where a window has no unchanged
+// line, all of it is emitted as changed. So lines are only aligned within a
+// window, and the result need not be minimal, nor the diff SideBySide would
+// compute, for changes that span more than a window. A chunkLines of less
+// than 1 counts as 1. If reading or emit fails, DiffChunked stops and returns
+// the error.
+func DiffChunked(a, b io.Reader, chunkLines int, emit func(SideBySideLine) error) error {
+	chunkLines = max(chunkLines, 1)
+	ra, rb := &chunkReader{r: bufio.NewReader(a)}, &chunkReader{r: bufio.NewReader(b)}
+	for {
+		if err := ra.fill(chunkLines); err != nil {
+			return err
+		}
+		if err := rb.fill(chunkLines); err != nil {
+			return err
+		}
+		lines := SideBySide(ra.lines, rb.lines)
+		end := len(lines) // the lines to emit
+		if !ra.eof || !rb.eof {
+			for end > 0 && lines[end-1].Type != NoChange {
+				end--
+			}
+			if end == 0 {
+				end = len(lines)
+			}
+		}
+		i, j := 0, 0 // the lines of a and b emitted
+		for _, l := range lines[:end] {
+			if err := emit(l); err != nil {
+				return err
+			}
+			if l.Type != Added {
+				i++
+			}
+			if l.Type != Deleted {
+				j++
+			}
+		}
+		ra.lines = append(ra.lines[:0], ra.lines[i:]...)
+		rb.lines = append(rb.lines[:0], rb.lines[j:]...)
+		if ra.eof && rb.eof && len(ra.lines) == 0 && len(rb.lines) == 0 {
+			return nil
+		}
+	}
+}
+
+// chunkReader holds the lines read from r that have not been diffed yet.
+type chunkReader struct {
+	r     *bufio.Reader
+	lines []string
+	eof   bool
+}
+
+// fill reads lines until it holds n of them or reaches the end of r.
+func (c *chunkReader) fill(n int) error {
+	for !c.eof && len(c.lines) < n {
+		line, err := c.r.ReadString('\n')
+		if line != "" {
+			c.lines = append(c.lines, strings.TrimSuffix(line, "\n"))
+		}
+		if err == io.EOF {
+			c.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
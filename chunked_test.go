@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDiffChunked(t *testing.T) {
+	a := numbers(100, map[int]string{5: "a", 40: "b", 41: "c", 99: "d"})
+	b := numbers(100, map[int]string{5: "x", 60: "y", 61: "z"})
+	b = append(b[:20], b[23:]...)
+	text := func(lines []string) string { return strings.Join(lines, "\n") + "\n" }
+
+	for _, chunk := range []int{0, 1, 2, 5, 10, 50, 200} {
+		var lines []SideBySideLine
+		err := DiffChunked(iotest.OneByteReader(strings.NewReader(text(a))), strings.NewReader(text(b)), chunk,
+			func(l SideBySideLine) error {
+				lines = append(lines, l)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("chunk %d: %v", chunk, err)
+		}
+		var left, right []string
+		for _, l := range lines {
+			if l.Type != Added {
+				left = append(left, l.Left)
+			}
+			if l.Type != Deleted {
+				right = append(right, l.Right)
+			}
+			if l.Type == NoChange && l.Left != l.Right {
+				t.Errorf("chunk %d: unchanged line %v", chunk, l)
+			}
+		}
+		if !reflect.DeepEqual(left, a) || !reflect.DeepEqual(right, b) {
+			t.Errorf("chunk %d: diff does not give back the files", chunk)
+		}
+		if want := SideBySide(a, b); chunk >= 100 && !reflect.DeepEqual(lines, want) {
+			t.Errorf("chunk %d:\nwant %v\nhave %v", chunk, want, lines)
+		}
+	}
+
+	errEmit := errors.New("emit")
+	err := DiffChunked(strings.NewReader(text(a)), strings.NewReader(text(b)), 10,
+		func(SideBySideLine) error { return errEmit })
+	if err != errEmit {
+		t.Errorf("want %v, have %v", errEmit, err)
+	}
+	errRead := errors.New("read")
+	err = DiffChunked(iotest.ErrReader(errRead), strings.NewReader(text(b)), 10,
+		func(SideBySideLine) error { return nil })
+	if err != errRead {
+		t.Errorf("want %v, have %v", errRead, err)
+	}
+}
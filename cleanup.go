@@ -0,0 +1,259 @@
+package diff
+
+// Cleanup
+
+// Cleanup rewrites the edit script ops, which must carry their Lines as those
+// returned by EditScriptLines do, to be easier to read for humans. If any op
+// does not carry its Lines, ops is returned as is. Cleanup works in two
+// steps.
+//
+// First, it merges changes separated by few unchanged lines: a run of
+// unchanged lines between two runs of changes is turned into a deletion and an
+// addition if it is no longer than the larger of the deletion and the addition
+// on either side of it. This step gives up minimality for fewer and larger
+// changes, so the edit script may get longer.
+//
+// Second, it shifts each run that only deletes or only adds lines up or down
+// as far as the lines around it allow without changing the result, for
+// example so that a removed "}" followed by a removed "{" becomes a removed
+// "{" followed by a removed "}". Among all possible positions, it picks the
+// one with the best boundaries, and the lowest of those. A boundary between
+// a line above and a line below it scores two points if the line above is
+// blank, and one point if the line below is not blank and not indented
+// more deeply than the line above. The top and bottom of the sequence count
+// as blank lines. This step does not change the length of the edit script.
+func Cleanup(ops []Op) []Op {
+	var es []element
+	for _, op := range ops {
+		if len(op.Lines) != op.Len {
+			return ops
+		}
+		for _, l := range op.Lines {
+			es = append(es, element{op.Kind, l})
+		}
+	}
+	es = mergeChanges(es)
+	for p := 0; p < len(es); {
+		q := p
+		for q < len(es) && es[q].kind == es[p].kind {
+			q++
+		}
+		if es[p].kind != NoChange && (p == 0 || es[p-1].kind == NoChange) && (q == len(es) || es[q].kind == NoChange) {
+			p, q = shift(es, p, q)
+		}
+		p = q
+	}
+	return elementOps(es)
+}
+
+// An element is a line of an edit script: a line of both sequences with
+// kind==NoChange, or a line deleted from the left or added to the right.
+type element struct {
+	kind int
+	text string
+}
+
+// mergeChanges turns short runs of unchanged elements between changes into
+// deletions and additions.
+func mergeChanges(es []element) []element {
+	for merged := true; merged; {
+		merged = false
+		var out []element
+		for p := 0; p < len(es); {
+			q := p
+			for q < len(es) && (es[q].kind == NoChange) == (es[p].kind == NoChange) {
+				q++
+			}
+			if es[p].kind == NoChange && p > 0 && q < len(es) {
+				before := largerChange(out)
+				r := q
+				for r < len(es) && es[r].kind != NoChange {
+					r++
+				}
+				if after := largerChange(es[q:r]); q-p <= before && q-p <= after {
+					start := len(out)
+					for start > 0 && out[start-1].kind != NoChange {
+						start--
+					}
+					run := append([]element(nil), out[start:]...)
+					for _, e := range es[p:q] {
+						run = append(run, element{Deleted, e.text}, element{Added, e.text})
+					}
+					run = append(run, es[q:r]...)
+					out = append(out[:start], orderChanges(run)...)
+					merged = true
+					p = r
+					continue
+				}
+			}
+			out = append(out, es[p:q]...)
+			p = q
+		}
+		es = out
+	}
+	return es
+}
+
+// largerChange returns the larger of the number of deleted and added elements
+// in the run of changes at the end of es.
+func largerChange(es []element) int {
+	del, add := 0, 0
+	for k := len(es) - 1; k >= 0 && es[k].kind != NoChange; k-- {
+		if es[k].kind == Deleted {
+			del++
+		} else {
+			add++
+		}
+	}
+	return max(del, add)
+}
+
+// orderChanges moves the deleted elements of a run of changes before the
+// added ones.
+func orderChanges(run []element) []element {
+	var out []element
+	for _, e := range run {
+		if e.kind == Deleted {
+			out = append(out, e)
+		}
+	}
+	for _, e := range run {
+		if e.kind == Added {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// shift moves the run es[p:q] of deleted or added elements to the position
+// with the best score and returns its new bounds. Moving the run over an
+// unchanged element with the same text only changes which of the two is
+// reported as unchanged, so it suffices to update kinds.
+func shift(es []element, p, q int) (int, int) {
+	kind := es[p].kind
+	for p > 0 && es[p-1].kind == NoChange && es[p-1].text == es[q-1].text {
+		p, q = p-1, q-1
+		es[p].kind, es[q].kind = kind, NoChange
+	}
+	best, bestScore := p, -1
+	for {
+		if s := shiftScore(es, p, q); s >= bestScore {
+			best, bestScore = p, s
+		}
+		if q == len(es) || es[q].kind != NoChange || es[q].text != es[p].text {
+			break
+		}
+		es[p].kind, es[q].kind = NoChange, kind
+		p, q = p+1, q+1
+	}
+	for p > best {
+		p, q = p-1, q-1
+		es[p].kind, es[q].kind = kind, NoChange
+	}
+	return p, q
+}
+
+// shiftScore returns the score of the boundaries of the run es[p:q] in the
+// sequence the run belongs to.
+func shiftScore(es []element, p, q int) int {
+	other := Added // kind of the elements not in the sequence of es[p]
+	if es[p].kind == Added {
+		other = Deleted
+	}
+	above, below := p-1, q
+	for above >= 0 && es[above].kind == other {
+		above--
+	}
+	for below < len(es) && es[below].kind == other {
+		below++
+	}
+	lineAbove, lineBelow := "", ""
+	if above >= 0 {
+		lineAbove = es[above].text
+	}
+	if below < len(es) {
+		lineBelow = es[below].text
+	}
+	return boundaryScore(lineAbove, es[p].text) + boundaryScore(es[q-1].text, lineBelow)
+}
+
+func boundaryScore(above, below string) int {
+	s := 0
+	if blankLine(above) {
+		s += 2
+	}
+	if !blankLine(below) && (blankLine(above) || indent(below) <= indent(above)) {
+		s++
+	}
+	return s
+}
+
+func blankLine(s string) bool {
+	for _, c := range s {
+		if c != ' ' && c != '\t' && c != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// indent returns the width of the leading white space of s, with tab stops
+// every 8 columns.
+func indent(s string) int {
+	w := 0
+	for _, c := range s {
+		switch c {
+		case ' ':
+			w++
+		case '\t':
+			w += 8 - w%8
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// elementOps returns the edit script of es.
+func elementOps(es []element) []Op {
+	var ops []Op
+	i, j := 0, 0
+	for p := 0; p < len(es); {
+		if es[p].kind == NoChange {
+			q := p
+			for q < len(es) && es[q].kind == NoChange {
+				q++
+			}
+			ops = append(ops, Op{NoChange, i, j, q - p, texts(es[p:q])})
+			i, j, p = i+q-p, j+q-p, q
+			continue
+		}
+		q := p
+		for q < len(es) && es[q].kind != NoChange {
+			q++
+		}
+		run := orderChanges(es[p:q])
+		del := 0
+		for del < len(run) && run[del].kind == Deleted {
+			del++
+		}
+		if del > 0 {
+			ops = append(ops, Op{Deleted, i, j, del, texts(run[:del])})
+			i += del
+		}
+		if add := len(run) - del; add > 0 {
+			ops = append(ops, Op{Added, i, j, add, texts(run[del:])})
+			j += add
+		}
+		p = q
+	}
+	return ops
+}
+
+func texts(es []element) []string {
+	lines := make([]string, len(es))
+	for k, e := range es {
+		lines[k] = e.text
+	}
+	return lines
+}
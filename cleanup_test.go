@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		ops  []Op
+	}{
+		// Removing a case from a table: Diff keeps the first "{" and removes
+		// the "}," and "{" around the case; Cleanup removes the case.
+		{
+			"\t{\n\t\t\"a\",\n\t},\n\t{\n\t\t\"b\",\n\t},",
+			"\t{\n\t\t\"b\",\n\t},",
+			[]Op{
+				{Deleted, 0, 0, 3, []string{"\t{", "\t\t\"a\",", "\t},"}},
+				{NoChange, 3, 0, 3, []string{"\t{", "\t\t\"b\",", "\t},"}},
+			},
+		},
+		// Adding a function: the run of added lines starts after a blank
+		// line and ends with one.
+		{
+			"}\n\nfunc b() {\n}",
+			"}\n\nfunc a() {\n}\n\nfunc b() {\n}",
+			[]Op{
+				{NoChange, 0, 0, 2, []string{"}", ""}},
+				{Added, 2, 2, 3, []string{"func a() {", "}", ""}},
+				{NoChange, 2, 5, 2, []string{"func b() {", "}"}},
+			},
+		},
+		// Changes separated by a single unchanged line are merged.
+		{
+			"x\nsame\ny",
+			"X\nsame\nY",
+			[]Op{
+				{Deleted, 0, 0, 3, []string{"x", "same", "y"}},
+				{Added, 3, 0, 3, []string{"X", "same", "Y"}},
+			},
+		},
+		// But not if the unchanged lines outnumber the changes.
+		{
+			"x\nsame\nsame\ny",
+			"X\nsame\nsame\nY",
+			[]Op{
+				{Deleted, 0, 0, 1, []string{"x"}},
+				{Added, 1, 0, 1, []string{"X"}},
+				{NoChange, 1, 1, 2, []string{"same", "same"}},
+				{Deleted, 3, 3, 1, []string{"y"}},
+				{Added, 4, 3, 1, []string{"Y"}},
+			},
+		},
+	}
+	for i, test := range tests {
+		ops := Cleanup(EditScriptLines(strings.Split(test.a, "\n"), strings.Split(test.b, "\n")))
+		if !reflect.DeepEqual(ops, test.ops) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.ops, ops)
+		}
+	}
+
+	ops := EditScript(&stringDiff{a: "abc", b: "ac"})
+	if have := Cleanup(ops); !reflect.DeepEqual(have, ops) {
+		t.Errorf("ops without lines:\nwant %v\nhave %v\n", ops, have)
+	}
+}
+
+func TestCleanupApply(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 10, "ab \t"), "")
+		b := strings.Split(randString(r, 10, "ab \t"), "")
+		ops := Cleanup(EditScriptLines(a, b))
+		have, err := Apply(a, ops)
+		if err != nil || !equalLines(have, b) {
+			t.Fatalf("Apply(%q, Cleanup(EditScriptLines(%q, %q))) = %q, %v", a, a, b, have, err)
+		}
+	}
+}
@@ -2,14 +2,51 @@
 // subsequence of two sequences.
 package diff
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strings"
+	"time"
+)
+
 // Constants used for SideBySide diffs.
 const (
 	NoChange = iota
 	Added
 	Deleted
 	Changed
+	Skipped
+	Moved
+	NearMatch
 )
 
+// A ChangeType is the Type of a SideBySideLine, one of the constants above.
+// The constants are untyped, so they can still be used where an int is
+// expected, as for the Kind of an Op.
+type ChangeType int
+
+var changeTypeNames = []string{
+	NoChange:  "NoChange",
+	Added:     "Added",
+	Deleted:   "Deleted",
+	Changed:   "Changed",
+	Skipped:   "Skipped",
+	Moved:     "Moved",
+	NearMatch: "NearMatch",
+}
+
+// String returns the name of the constant t, such as "Added".
+func (t ChangeType) String() string {
+	if t < 0 || int(t) >= len(changeTypeNames) {
+		return fmt.Sprintf("ChangeType(%d)", int(t))
+	}
+	return changeTypeNames[t]
+}
+
 // A type that implements diff.Interface can be passed to the Diff function to
 // find the largest common subsequence in two sequences.
 type Interface interface {
@@ -30,91 +67,609 @@ type Interface interface {
 // the length of the edit script (number of inserts and deletes) needed to go
 // from one sequence to the other. The algorithm is described here:
 // http://neil.fraser.name/software/diff_match_patch/myers.pdf.
+// Where a change could be placed at several positions within a run of equal
+// elements, which one Diff chooses is not specified; DiffStablePosition
+// always chooses the last.
 func Diff(data Interface) int {
-	var vs [][]int
+	d, err := DiffContext(context.Background(), data)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// ErrNoPath is returned by DiffSafe if the algorithm fails, which happens only
+// for an Interface whose methods are inconsistent.
+var ErrNoPath = errors.New("diff: no path found")
+
+// ErrNegativeLength is returned by DiffSafe if Lengths returns a negative
+// length.
+var ErrNegativeLength = errors.New("diff: negative length")
+
+// ErrTooLarge is returned by DiffSafe if the sequences are too long for the
+// positions in them to be computed without overflow, and by DiffLimited if
+// they are too long to be diffed within its limit.
+var ErrTooLarge = errors.New("diff: input too large")
+
+// maxLength is the largest total length of two sequences whose positions the
+// algorithms can compute without overflowing an int.
+const maxLength = (math.MaxInt - 3) / 2
+
+// checkLengths returns the error for sequences of lengths n and m that cannot
+// be diffed.
+func checkLengths(n, m int) error {
+	if n < 0 || m < 0 {
+		return ErrNegativeLength
+	}
+	if n > maxLength-m {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// DiffSafe is like Diff but returns an error instead of panicking. Panics in
+// the methods of data, for example because Equal is called with indices that
+// are out of range for lengths that Lengths got wrong, are returned as errors
+// as well, wrapping the panic's value if it is an error.
+func DiffSafe(data Interface) (edits int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			edits, err = 0, panicError(r)
+		}
+	}()
+	return DiffContext(context.Background(), data)
+}
+
+// panicError returns the error DiffSafe returns for the recovered panic value
+// r.
+func panicError(r any) error {
+	if e, ok := r.(error); ok {
+		return fmt.Errorf("diff: %w", e)
+	}
+	return fmt.Errorf("diff: %v", r)
+}
+
+// DiffContext is like Diff but gives up and returns ctx.Err() if ctx is done
+// before the longest common subsequence has been found, in which case
+// data.Common is not called. ctx is checked once for every increase of the
+// edit distance considered.
+func DiffContext(ctx context.Context, data Interface) (int, error) {
+	return diff(ctx, data, math.MaxInt, nil)
+}
+
+// DiffDeadline is like DiffContext with a context that times out after d:
+// it gives up and returns context.DeadlineExceeded if the longest common
+// subsequence has not been found within d, in which case data.Common is not
+// called.
+func DiffDeadline(data Interface, d time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return DiffContext(ctx, data)
+}
+
+// DiffProgress is like Diff but calls progress(d, n+m) for sequences of
+// lengths n and m before considering each edit distance d in turn, starting
+// at 0. The edit distance found is at most n+m, so d/(n+m) tells how far the
+// search has come, although the time it takes grows with the square of d.
+// progress is not called once the edit distance is known, nor at all if one
+// of the sequences is empty.
+func DiffProgress(data Interface, progress func(d, max int)) int {
+	d, err := diff(context.Background(), data, math.MaxInt, progress)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DiffBounded is like Diff but gives up as soon as it is clear that the edit
+// distance exceeds max, returning max+1 and false without calling
+// data.Common. The time it takes grows with the smaller of max and the edit
+// distance rather than with the edit distance alone.
+func DiffBounded(data Interface, max int) (int, bool) {
+	d, err := diff(context.Background(), data, max, nil)
+	if err == errTooFar {
+		return max + 1, false
+	}
+	if err != nil {
+		panic(err)
+	}
+	return d, true
+}
+
+// DiffOrBail is like Diff but treats the sequences as unrelated, returning 0
+// and false without calling data.Common, if the longer one is more than
+// maxLenRatio times as long as the shorter one. An empty sequence is
+// infinitely shorter than one that is not, so only a maxLenRatio of +Inf
+// lets it be diffed with one that is not.
+func DiffOrBail(data Interface, maxLenRatio float64) (int, bool) {
 	n, m := data.Lengths()
+	long, short := float64(max(n, m)), float64(min(n, m))
+	if long > 0 && !math.IsInf(maxLenRatio, 1) && !(long <= maxLenRatio*short) {
+		return 0, false
+	}
+	return Diff(data), true
+}
+
+// DiffLimited is like DiffSafe but refuses to diff sequences for which Diff
+// could need memory for more than maxCells positions, returning ErrTooLarge
+// before calling any method of data other than Lengths. To find an edit
+// distance of d, Diff keeps up to (d+1)*(d+2)/2 positions; for sequences of
+// lengths n and m, d is at most n+m, and no positions are needed if one of
+// them is empty.
+func DiffLimited(data Interface, maxCells int) (int, error) {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		return 0, err
+	}
+	if d := uint64(n + m); n > 0 && m > 0 {
+		hi, lo := bits.Mul64(d+1, d+2)
+		if hi != 0 || maxCells < 0 || lo/2 > uint64(maxCells) {
+			return 0, ErrTooLarge
+		}
+	}
+	return DiffSafe(data)
+}
+
+// DiffReverseOrder is like Diff but calls data.Common for the parts of the
+// same common subsequence from bottom to top. The first call reports the
+// trailing elements that are equal, even if there are none, and the last one
+// the first part of the subsequence.
+func DiffReverseOrder(data Interface) int {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	if n == 0 || m == 0 {
+		data.Common(n, m, 0)
+		return n + m
+	}
+	vs, err := search(context.Background(), data, n, m, math.MaxInt, true, nil)
+	if err != nil {
+		panic(err)
+	}
+	path := trace(vs, n, m, len(vs)-1)
+	for k := len(path) - 1; k >= 0; k-- {
+		if x, y, l := path[k][0], path[k][1], path[k][2]; l > 0 || (x+l == n && y+l == m) {
+			data.Common(x, y, l)
+		}
+	}
+	return len(vs) - 1
+}
+
+// DiffFast is like Diff but first checks whether one of the sequences is a
+// prefix of the other, including the case that one of them is empty. The
+// common subsequence is then that prefix and is reported without searching
+// for it, with the same calls of data.Common that Diff makes; that saves the
+// O(d²) memory the search needs for an edit distance of d. Otherwise DiffFast
+// falls back to Diff. A sequence that is a suffix or another part of the
+// other is not treated specially, because Diff need not align it as a whole:
+// "a" and "baa" share the first "a" of "aa", for example.
+func DiffFast(data Interface) int {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	k := min(n, m)
+	for i := 0; i < k; i++ {
+		if !data.Equal(i, i) {
+			return Diff(data)
+		}
+	}
+	if n == m {
+		data.Common(0, 0, n)
+		return 0
+	}
+	if k > 0 {
+		data.Common(0, 0, k)
+	}
+	data.Common(n, m, 0)
+	return n + m - 2*k
+}
+
+// DiffDistance returns the length of the edit script Diff returns for data,
+// without computing the common subsequence, so data.Common is not called.
+// Diff keeps memory for every step of its search to trace the path back;
+// DiffDistance only for the last one, which for sequences with an edit
+// distance of d is O(d) rather than O(d²).
+func DiffDistance(data Interface) int {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	if n == 0 || m == 0 {
+		return n + m
+	}
+	vs, err := search(context.Background(), data, n, m, math.MaxInt, false, nil)
+	if err != nil {
+		panic(err)
+	}
+	return len(vs) - 1
+}
+
+// errTooFar is returned by diff when the edit distance exceeds max.
+var errTooFar = errors.New("diff: edit distance too large")
+
+// diff implements Diff, giving up when ctx is done or the edit distance
+// exceeds max, and reporting progress to progress if it is not nil.
+func diff(ctx context.Context, data Interface, max int, progress func(d, max int)) (int, error) {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		return 0, err
+	}
+	if n == 0 || m == 0 {
+		// Nothing in common, which the search would take O((n+m)²) to
+		// find out.
+		if n+m > max {
+			return max + 1, errTooFar
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		data.Common(n, m, 0)
+		return n + m, nil
+	}
+	vs, err := search(ctx, data, n, m, max, true, progress)
+	if err == errTooFar {
+		return max + 1, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range trace(vs, n, m, len(vs)-1) {
+		if x, y, l := s[0], s[1], s[2]; l > 0 || (x+l == n && y+l == m) {
+			data.Common(x, y, l)
+		}
+	}
+	return len(vs) - 1, nil
+}
+
+// search runs the forward search of the algorithm on the non-empty sequences
+// of data of lengths n and m. It returns the furthest x reached on each
+// diagonal for each edit distance up to the one that reaches (n, m). Unless
+// keep is set, only the last of these is kept, as the others are only needed
+// for the backtrace. If progress is not nil, it is called with each edit
+// distance before it is considered.
+func search(ctx context.Context, data Interface, n, m, max int, keep bool, progress func(d, max int)) ([][]int, error) {
+	// The common prefix is the snake on diagonal 0 that the search starts
+	// with. The common suffix cannot simply be cut off, because the search
+	// may align some of its lines with earlier ones, but a path that reaches
+	// it on the diagonal ending in (n, m) can skip to the end.
+	pre := 0
+	for pre < n && pre < m && data.Equal(pre, pre) {
+		pre++
+	}
+	suf := 0
+	for suf < n-pre && suf < m-pre && data.Equal(n-1-suf, m-1-suf) {
+		suf++
+	}
+
+	var vs [][]int
 	for d := 0; d <= m+n; d++ {
-		v := make([]int, 2*(n+m)+3) // at least 3 diagonals for the initial step
-		if d == 0 {
-			v[1] = 0
-		} else {
-			copy(v, vs[d-1])
+		if d > max {
+			return nil, errTooFar
 		}
-		for k := -d; k <= d; k += 2 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(d, n+m)
+		}
+		// v[(k-lo)/2] is the furthest x reached on diagonal k; only every
+		// other diagonal in -d..d can be reached with d edits, and only
+		// those in lo..hi without leaving the edit graph.
+		lo, hi := diagonals(d, n, m)
+		v := make([]int, (hi-lo)/2+1)
+		for k := lo; k <= hi; k += 2 {
 			var x int
-			K := len(v)/2 + k
-			if k == -d || (k != d && v[K-1] < v[K+1]) {
-				x = v[K+1]
-			} else {
-				x = v[K-1] + 1
+			switch {
+			case d == 0:
+				x = pre
+			case down(vs[d-1], d, k, n, m):
+				x = vs[d-1][index(k+1, d-1, n, m)] // down from diagonal k+1
+			default:
+				x = vs[d-1][index(k-1, d-1, n, m)] + 1 // right from diagonal k-1
 			}
 			y := x - k
+			if k == n-m && x >= n-suf {
+				x, y = n, m
+			}
 			for x < n && y < m && data.Equal(x, y) {
 				x++
 				y++
 			}
-			v[K] = x
+			v[(k-lo)/2] = x
 			if x >= n && y >= m {
-				vs = append(vs, v)
-				common(data, vs, n, m, len(vs)-1)
-				return len(vs) - 1
+				return append(vs, v), nil
 			}
 		}
+		if !keep && d > 0 {
+			vs[d-1] = nil
+		}
 		vs = append(vs, v)
 	}
-	panic("diff: no path found")
+	return nil, ErrNoPath
+}
+
+// diagonals returns the lowest and the highest diagonal x-y on which a
+// point of the edit graph for sequences of lengths n and m can be reached
+// with d edits.
+func diagonals(d, n, m int) (lo, hi int) {
+	lo, hi = -d, d
+	if d > m {
+		lo = -m + (d-m)%2
+	}
+	if d > n {
+		hi = n - (d-n)%2
+	}
+	return lo, hi
+}
+
+// index returns the index of diagonal k in the furthest points reached with
+// d edits.
+func index(k, d, n, m int) int {
+	lo, _ := diagonals(d, n, m)
+	return (k - lo) / 2
+}
+
+// down returns whether the furthest point on diagonal k reached with d > 0
+// edits is reached by a step down from diagonal k+1, rather than by one right
+// from diagonal k-1, given the furthest points v reached with d-1 edits.
+func down(v []int, d, k, n, m int) bool {
+	lo, hi := diagonals(d-1, n, m)
+	return k-1 < lo || (k+1 <= hi && v[(k-1-lo)/2] < v[(k+1-lo)/2])
+}
+
+// trace returns the snakes {x, y, l} from (x, y) to (x+l, y+l) reached with
+// each number of edits up to d on the path that search found to (n, m), from
+// top to bottom. Consecutive snakes are one edit apart. The start of each
+// snake follows from vs, so the backtrace does not call Equal. The path is
+// traced back from (n, m) into a slice rather than by recursion, as d can be
+// as large as n+m.
+func trace(vs [][]int, n, m, d int) [][3]int {
+	path := make([][3]int, d+1) // path[d]: the snake reached with d edits
+	x1, y1 := n, m
+	for ; d >= 0; d-- {
+		k := x1 - y1
+
+		var x, y int // end of the snake reached with d-1 edits
+		xm := 0      // start of the snake ending in (x1, y1)
+		if d > 0 {
+			if v := vs[d-1]; down(v, d, k, n, m) {
+				x = v[index(k+1, d-1, n, m)]
+				y = x - (k + 1)
+				xm = x
+			} else {
+				x = v[index(k-1, d-1, n, m)]
+				y = x - (k - 1)
+				xm = x + 1
+			}
+		}
+		l := x1 - xm
+		path[d] = [3]int{xm, y1 - l, l}
+		x1, y1 = x, y
+	}
+	return path
 }
 
-func common(data Interface, vs [][]int, x1, y1, d int) {
-	v := vs[d]
-	k := x1 - y1
-	K := len(v)/2 + k
+// runs collects the parts of a common subsequence found by an algorithm that
+// does not discover them in a single top-to-bottom sweep. Adjacent parts are
+// merged, so data.Common sees the same maximal runs Diff would report.
+type runs struct {
+	data    Interface
+	i, j, n int
+	total   int // sum of all reported n
+}
 
-	var x, y, xm int
-	if insert := k == -d || (k != d && v[K-1] < v[K+1]); insert {
-		x = v[K+1]
-		y = x - (k + 1)
-		xm = x
-	} else {
-		x = v[K-1]
-		y = x - (k - 1)
-		xm = x + 1
+// add records that left[i:i+n] == right[j:j+n]. Parts must be added from top
+// to bottom.
+func (r *runs) add(i, j, n int) {
+	if n == 0 {
+		return
 	}
-	if d > 0 {
-		common(data, vs, x, y, d-1)
+	r.total += n
+	if r.n > 0 && r.i+r.n == i && r.j+r.n == j {
+		r.n += n
+		return
 	}
-	if n := x1 - xm; n > 0 || d == len(vs)-1 {
-		data.Common(xm, y1-n, n)
+	if r.n > 0 {
+		r.data.Common(r.i, r.j, r.n)
 	}
+	r.i, r.j, r.n = i, j, n
 }
 
+// flush reports the pending part and makes the final call to data.Common
+// for sequences of lengths n and m.
+func (r *runs) flush(n, m int) {
+	if r.n > 0 {
+		r.data.Common(r.i, r.j, r.n)
+		if r.i+r.n == n && r.j+r.n == m {
+			return
+		}
+	}
+	r.data.Common(n, m, 0)
+}
+
+// window is the part left[i0:i1], right[j0:j1] of data as an Interface that
+// adds its common parts to runs, so that Diff can be run on it.
+type window struct {
+	data   Interface
+	runs   *runs
+	i0, i1 int
+	j0, j1 int
+}
+
+func (w *window) Lengths() (int, int) { return w.i1 - w.i0, w.j1 - w.j0 }
+func (w *window) Equal(i, j int) bool { return w.data.Equal(w.i0+i, w.j0+j) }
+func (w *window) Common(i, j, n int)  { w.runs.add(w.i0+i, w.j0+j, n) }
+
 // Side-by-side diff
 
 // SideBySideLine represents a line in a side-by-side diff.
 type SideBySideLine struct {
-	Left  string // Left line, empty string if Type==Added.
-	Right string // Right line, empty string if Type==Deleted.
-	Type  int    // NoChange, Added, Deleted, Changed
+	Left   string     `json:"left"`             // Left line, empty string if Type==Added.
+	Right  string     `json:"right"`            // Right line, empty string if Type==Deleted.
+	Type   ChangeType `json:"type"`             // NoChange, Added, Deleted, Changed, Skipped, Moved, NearMatch
+	Count  int        `json:"count,omitempty"`  // Number of unchanged lines left out if Type==Skipped.
+	MoveID int        `json:"moveId,omitempty"` // Identifies both ends of a move if Type==Moved.
+
+	// LeftSpans and RightSpans divide Left and Right into the parts that
+	// are common to both and those that have changed. They are only set by
+	// SideBySideDetailed.
+	LeftSpans  []Span `json:"leftSpans,omitempty"`
+	RightSpans []Span `json:"rightSpans,omitempty"`
+
+	// Continuation marks a line that continues the one before it, as split
+	// by Wrap.
+	Continuation bool `json:"continuation,omitempty"`
 }
 
-// SideBySide computes a side-by-side diff of two sets of lines.
+// SideBySide computes a side-by-side diff of two sets of lines. The result
+// depends on nothing but a and b, so it is the same on every run.
 func SideBySide(a, b []string) []SideBySideLine {
-	d := &sideBySide{a: a, b: b}
+	return SideBySideResult(a, b).Lines
+}
+
+// SideBySideN is like SideBySide but also returns the length of the edit
+// script from a to b, as Diff does.
+func SideBySideN(a, b []string) ([]SideBySideLine, int) {
+	r := SideBySideResult(a, b)
+	return r.Lines, r.Edits
+}
+
+// SideBySideFunc is like SideBySide but uses eq to decide whether two lines
+// are equal. Lines that are equal but differ in their text, for example in
+// whitespace, are reported as NoChange with their Left and Right text as in a
+// and b.
+func SideBySideFunc(a, b []string, eq func(x, y string) bool) []SideBySideLine {
+	d := &sideBySide{a: a, b: b, eq: eq}
+	Diff(d)
+	return d.lines
+}
+
+// SideBySideNear is like SideBySideFunc but reports lines that are equal
+// according to eq but not according to the stricter strictEq as NearMatch
+// rather than NoChange, for example to show lines that only differ in
+// whitespace in a diff that ignores it. The NoChange and NearMatch lines
+// together are a longest common subsequence under eq.
+func SideBySideNear(a, b []string, eq, strictEq func(x, y string) bool) []SideBySideLine {
+	d := &sideBySide{a: a, b: b, eq: eq, strictEq: strictEq}
+	Diff(d)
+	return d.lines
+}
+
+// SideBySideFold is like SideBySide but ignores differences in case, with
+// lines compared by EqualFold.
+func SideBySideFold(a, b []string) []SideBySideLine {
+	return SideBySideFunc(a, b, EqualFold)
+}
+
+// SideBySideNormalizeEOL is like SideBySide but ignores a "\r" at the end of
+// lines, so that files with CRLF and LF line endings compare equal. The lines
+// of the result keep their "\r".
+func SideBySideNormalizeEOL(a, b []string) []SideBySideLine {
+	return SideBySideFunc(a, b, func(x, y string) bool {
+		return strings.TrimSuffix(x, "\r") == strings.TrimSuffix(y, "\r")
+	})
+}
+
+// A SideBySideMode selects how SideBySideOpts shows lines that have been
+// replaced.
+type SideBySideMode int
+
+const (
+	PairChanged       SideBySideMode = iota // Pair deleted and added lines into Changed lines, like SideBySide.
+	SeparateAddDelete                       // Show deleted lines as Deleted, followed by the added lines as Added.
+)
+
+// SideBySideOpts is like SideBySide but shows replaced lines according to
+// mode.
+func SideBySideOpts(a, b []string, mode SideBySideMode) []SideBySideLine {
+	d := &sideBySide{a: a, b: b, ha: HashLines(a), hb: HashLines(b), mode: mode}
 	Diff(d)
 	return d.lines
 }
 
+// SideBySidePairLimit is like SideBySide but pairs at most maxPair of the
+// deleted and added lines of each run of changes into Changed lines. The
+// remaining deleted lines follow as Deleted, and then the remaining added
+// lines as Added, so that large unrelated blocks do not show as changed line
+// by line. A maxPair of math.MaxInt gives the result of SideBySide, and one of
+// zero or less that of SideBySideOpts with SeparateAddDelete.
+func SideBySidePairLimit(a, b []string, maxPair int) []SideBySideLine {
+	d := &sideBySide{a: a, b: b, ha: HashLines(a), hb: HashLines(b), limitPairs: true, maxPair: maxPair}
+	Diff(d)
+	return d.lines
+}
+
+// EqualFold reports whether the lines x and y are equal under Unicode case
+// folding. It can be passed to SideBySideFunc.
+func EqualFold(x, y string) bool {
+	return strings.EqualFold(x, y)
+}
+
+// SideBySideStream is like SideBySide but passes the lines to emit one by one
+// instead of collecting them. If emit returns an error, no more lines are
+// emitted and SideBySideStream returns the error. Note that the diff is
+// computed before the first line is emitted; streaming only saves holding on
+// to all lines of the result.
+func SideBySideStream(a, b []string, emit func(SideBySideLine) error) error {
+	d := &sideBySide{a: a, b: b, ha: HashLines(a), hb: HashLines(b), emit: emit}
+	Diff(d)
+	return d.err
+}
+
 type sideBySide struct {
-	a     []string
-	b     []string
-	i     int
-	j     int
-	lines []SideBySideLine
+	a          []string
+	b          []string
+	eq         func(x, y string) bool // if nil, lines are compared using ha and hb
+	strictEq   func(x, y string) bool // if not nil, common lines it finds different are NearMatch
+	ha         []uint64               // hashes of a, see HashLines
+	hb         []uint64
+	mode       SideBySideMode
+	limitPairs bool // pair at most maxPair lines of each run of changes
+	maxPair    int
+	i          int
+	j          int
+	emit       func(SideBySideLine) error // if nil, lines are collected in lines
+	err        error                      // returned by emit
+	lines      []SideBySideLine
+}
+
+func (d *sideBySide) add(line SideBySideLine) {
+	switch {
+	case d.err != nil:
+	case d.emit != nil:
+		d.err = d.emit(line)
+	default:
+		d.lines = append(d.lines, line)
+	}
 }
 
 func (d *sideBySide) Lengths() (int, int) { return len(d.a), len(d.b) }
-func (d *sideBySide) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d *sideBySide) Equal(i, j int) bool {
+	if d.eq != nil {
+		return d.eq(d.a[i], d.b[j])
+	}
+	return d.ha[i] == d.hb[j] && d.a[i] == d.b[j]
+}
 func (d *sideBySide) Common(i, j, n int) {
+	if d.mode == SeparateAddDelete || d.limitPairs {
+		pairs := 0
+		if d.mode != SeparateAddDelete {
+			pairs = max(0, min(d.maxPair, i-d.i, j-d.j))
+		}
+		for ; pairs > 0; pairs-- {
+			d.add(SideBySideLine{Left: d.a[d.i], Right: d.b[d.j], Type: Changed})
+			d.i++
+			d.j++
+		}
+		for ; d.i < i; d.i++ {
+			d.add(SideBySideLine{Left: d.a[d.i], Type: Deleted})
+		}
+		for ; d.j < j; d.j++ {
+			d.add(SideBySideLine{Right: d.b[d.j], Type: Added})
+		}
+	}
 	for d.i < i || d.j < j {
 		var line SideBySideLine
 		switch {
@@ -133,19 +688,39 @@ func (d *sideBySide) Common(i, j, n int) {
 			line.Right = d.b[d.j]
 			d.j++
 		}
-		d.lines = append(d.lines, line)
+		d.add(line)
 	}
 	for ; n > 0; n-- {
-		d.lines = append(d.lines, SideBySideLine{
+		typ := ChangeType(NoChange)
+		if d.strictEq != nil && !d.strictEq(d.a[d.i], d.b[d.j]) {
+			typ = NearMatch
+		}
+		d.add(SideBySideLine{
 			Left:  d.a[d.i],
 			Right: d.b[d.j],
-			Type:  NoChange,
+			Type:  typ,
 		})
 		d.i++
 		d.j++
 	}
 }
 
+// HashLines returns the 64-bit FNV-1a hashes of lines. Lines with different
+// hashes are different, so comparing hashes first saves comparing long lines
+// that are not equal; lines with equal hashes still have to be compared.
+func HashLines(lines []string) []uint64 {
+	hs := make([]uint64, len(lines))
+	for i, l := range lines {
+		h := uint64(14695981039346656037)
+		for k := 0; k < len(l); k++ {
+			h ^= uint64(l[k])
+			h *= 1099511628211
+		}
+		hs[i] = h
+	}
+	return hs
+}
+
 // Annotated diff
 
 // AnnotatedLine represents a line in an annotated diff.
@@ -158,24 +733,154 @@ type AnnotatedLine struct {
 // each line the version in which it was introduced. version is an int
 // representing b's version.
 func Annotate(a []AnnotatedLine, b []string, version int) []AnnotatedLine {
-	d := &annotate{a: a, b: b, version: version}
+	d := &annotate[AnnotatedLine]{
+		a:    a,
+		b:    b,
+		text: func(l AnnotatedLine) string { return l.Text },
+		line: func(text string) AnnotatedLine { return AnnotatedLine{text, version} },
+	}
 	Diff(d)
 	return d.lines
 }
 
-type annotate struct {
-	a       []AnnotatedLine
+// A Deletion records a line that has been deleted: Text was introduced in
+// FromVersion and deleted in AtVersion.
+type Deletion struct {
+	Text        string
+	FromVersion int
+	AtVersion   int
+}
+
+// AnnotateWithDeletions is like Annotate but also returns the lines of a that
+// are deleted in b, from top to bottom, as Deletions at version.
+func AnnotateWithDeletions(a []AnnotatedLine, b []string, version int) ([]AnnotatedLine, []Deletion) {
+	var dels []Deletion
+	d := &annotate[AnnotatedLine]{
+		a:    a,
+		b:    b,
+		text: func(l AnnotatedLine) string { return l.Text },
+		line: func(text string) AnnotatedLine { return AnnotatedLine{text, version} },
+		deleted: func(l AnnotatedLine) {
+			dels = append(dels, Deletion{l.Text, l.Version, version})
+		},
+	}
+	Diff(d)
+	return d.lines, dels
+}
+
+// Blame annotates the last of versions with the index of the version in which
+// each of its lines was introduced, by calling Annotate for each version in
+// turn.
+func Blame(versions [][]string) []AnnotatedLine {
+	var a Annotator
+	for v, b := range versions {
+		a.Add(b, v)
+	}
+	return a.Lines()
+}
+
+// An Annotator annotates a file version by version, as repeated calls to
+// Annotate do, but reuses the memory of the annotated lines. The zero value
+// is an Annotator for a file without lines.
+type Annotator struct {
+	// TrackRevert, if not nil, lets blame follow reverts. It is called for
+	// each line Add introduces whose text has been introduced before, with
+	// the versions in which it was, in the order they were first recorded,
+	// and returns the version to record for the line instead of the one
+	// passed to Add. candidates must not be modified.
+	TrackRevert func(text string, candidates []int) int
+
+	lines   []AnnotatedLine
+	spare   []AnnotatedLine  // the lines before the last Add, for reuse
+	history map[string][]int // the versions recorded for each text, if TrackRevert is set
+}
+
+// Add annotates b as the next version of the file, introduced in version.
+func (a *Annotator) Add(b []string, version int) {
+	var added []AnnotatedLine
+	d := &annotate[AnnotatedLine]{
+		a:    a.lines,
+		b:    b,
+		text: func(l AnnotatedLine) string { return l.Text },
+		line: func(text string) AnnotatedLine {
+			if a.TrackRevert == nil {
+				return AnnotatedLine{text, version}
+			}
+			l := AnnotatedLine{text, version}
+			if vs := a.history[text]; len(vs) > 0 {
+				l.Version = a.TrackRevert(text, vs)
+			}
+			added = append(added, l)
+			return l
+		},
+		lines: a.spare[:0],
+	}
+	Diff(d)
+	a.lines, a.spare = d.lines, a.lines
+	for _, l := range added {
+		if a.history == nil {
+			a.history = map[string][]int{}
+		}
+		if vs := a.history[l.Text]; !slices.Contains(vs, l.Version) {
+			a.history[l.Text] = append(vs, l.Version)
+		}
+	}
+}
+
+// Lines returns the annotated lines of the last version added. Later calls to
+// Add and Reset reuse the slice, so it must be copied to be kept.
+func (a *Annotator) Lines() []AnnotatedLine {
+	return a.lines
+}
+
+// Reset makes a start over with a file without lines, so that it can be
+// used for another file. The history of TrackRevert is cleared as well.
+func (a *Annotator) Reset() {
+	a.lines, a.spare = a.lines[:0], a.spare[:0]
+	clear(a.history)
+}
+
+// AnnotatedLineOf represents a line in an annotated diff with arbitrary
+// metadata, such as the author and time of the change that introduced it.
+type AnnotatedLineOf[M any] struct {
+	Text string
+	Meta M
+}
+
+// AnnotateWith is like Annotate but records meta, rather than a version, for
+// the lines introduced in b. Lines of a that survive keep their metadata.
+func AnnotateWith[M any](a []AnnotatedLineOf[M], b []string, meta M) []AnnotatedLineOf[M] {
+	d := &annotate[AnnotatedLineOf[M]]{
+		a:    a,
+		b:    b,
+		text: func(l AnnotatedLineOf[M]) string { return l.Text },
+		line: func(text string) AnnotatedLineOf[M] { return AnnotatedLineOf[M]{text, meta} },
+	}
+	Diff(d)
+	return d.lines
+}
+
+// annotate computes annotated lines of type L.
+type annotate[L any] struct {
+	a       []L
 	b       []string
+	i       int
 	j       int
-	version int
-	lines   []AnnotatedLine
+	text    func(L) string      // returns the text of an annotated line
+	line    func(text string) L // returns an annotated line introduced in b
+	deleted func(L)             // if not nil, called for the lines of a deleted in b
+	lines   []L
 }
 
-func (d *annotate) Lengths() (int, int) { return len(d.a), len(d.b) }
-func (d *annotate) Equal(i, j int) bool { return d.a[i].Text == d.b[j] }
-func (d *annotate) Common(i, j, n int) {
+func (d *annotate[L]) Lengths() (int, int) { return len(d.a), len(d.b) }
+func (d *annotate[L]) Equal(i, j int) bool { return d.text(d.a[i]) == d.b[j] }
+func (d *annotate[L]) Common(i, j, n int) {
+	for ; d.deleted != nil && d.i < i; d.i++ {
+		d.deleted(d.a[d.i])
+	}
+	d.i = i + n
 	for d.j < j {
-		d.lines = append(d.lines, AnnotatedLine{d.b[d.j], d.version})
+		d.lines = append(d.lines, d.line(d.b[d.j]))
 		d.j++
 	}
 	d.lines = append(d.lines, d.a[i:i+n]...)
@@ -1,9 +1,17 @@
 package diff
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
 	"testing"
+	"time"
 )
 
 type stringDiff struct {
@@ -20,29 +28,44 @@ func (d *stringDiff) Common(i, j, n int) {
 	d.lcsb = append(d.lcsb, d.b[j:j+n])
 }
 
+// lineDiff records the Common calls for two sequences of lines.
+type lineDiff struct {
+	a      []string
+	b      []string
+	common [][3]int
+}
+
+func (d *lineDiff) Lengths() (int, int) { return len(d.a), len(d.b) }
+func (d *lineDiff) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d *lineDiff) Common(i, j, n int)  { d.common = append(d.common, [3]int{i, j, n}) }
+
+var diffTests = []struct {
+	a     string
+	b     string
+	lcs   []string
+	edits int
+}{
+	{"", "", []string{""}, 0},
+	{"", "a", []string{""}, 1},
+	{"a", "", []string{""}, 1},
+	{"a", "a", []string{"a"}, 0},
+	{"ab", "a", []string{"a", ""}, 1},
+	{"a", "ab", []string{"a", ""}, 1},
+	{"abc", "abc", []string{"abc"}, 0},
+	{"abc", "ac", []string{"a", "c"}, 1},
+	{"bc", "abc", []string{"bc"}, 1},
+	{"ab", "abc", []string{"ab", ""}, 1},
+	{"abcdefghijk", "abxyzcdxyzfgxyzj", []string{"ab", "cd", "fg", "j", ""}, 13},
+}
+
 func TestDiff(t *testing.T) {
-	var tests = []struct {
-		a     string
-		b     string
-		lcs   []string
-		edits int
-	}{
-		{"", "", []string{""}, 0},
-		{"", "a", []string{""}, 1},
-		{"a", "", []string{""}, 1},
-		{"a", "a", []string{"a"}, 0},
-		{"ab", "a", []string{"a", ""}, 1},
-		{"a", "ab", []string{"a", ""}, 1},
-		{"abc", "abc", []string{"abc"}, 0},
-		{"abc", "ac", []string{"a", "c"}, 1},
-		{"bc", "abc", []string{"bc"}, 1},
-		{"ab", "abc", []string{"ab", ""}, 1},
-		{"abcdefghijk", "abxyzcdxyzfgxyzj", []string{"ab", "cd", "fg", "j", ""}, 13},
-	}
+	testDiff(t, Diff)
+}
 
-	for i, test := range tests {
+func testDiff(t *testing.T, diff func(Interface) int) {
+	for i, test := range diffTests {
 		d := &stringDiff{a: test.a, b: test.b}
-		edits := Diff(d)
+		edits := diff(d)
 		if !reflect.DeepEqual(d.lcsa, test.lcs) {
 			t.Errorf("test %d lcsa:\nwant %q\nhave %q\n", i, test.lcs, d.lcsa)
 		}
@@ -67,24 +90,24 @@ func TestSideBySide(t *testing.T) {
 	}, {
 		[]string{"a", "b"},
 		[]string{"a", "c"},
-		[]SideBySideLine{{"a", "a", NoChange}, {"b", "c", Changed}},
+		[]SideBySideLine{{Left: "a", Right: "a", Type: NoChange}, {Left: "b", Right: "c", Type: Changed}},
 	}, {
 		[]string{"a", "b"},
 		[]string{"b"},
-		[]SideBySideLine{{"a", "", Deleted}, {"b", "b", NoChange}},
+		[]SideBySideLine{{Left: "a", Type: Deleted}, {Left: "b", Right: "b", Type: NoChange}},
 	}, {
 		[]string{"a", "b"},
 		[]string{"a", "c", "b"},
-		[]SideBySideLine{{"a", "a", NoChange}, {"", "c", Added}, {"b", "b", NoChange}},
+		[]SideBySideLine{{Left: "a", Right: "a", Type: NoChange}, {Right: "c", Type: Added}, {Left: "b", Right: "b", Type: NoChange}},
 	}, {
 		[]string{"a"},
 		[]string{"b", "c"},
-		[]SideBySideLine{{"a", "b", Changed}, {"", "c", Added}},
+		[]SideBySideLine{{Left: "a", Right: "b", Type: Changed}, {Right: "c", Type: Added}},
 	}}
 	for i, test := range tests {
 		lines := SideBySide(test.a, test.b)
 		if !reflect.DeepEqual(lines, test.lines) {
-			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.lines, lines)
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.lines, lines)
 		}
 	}
 }
@@ -109,3 +132,591 @@ func ExampleAnnotate() {
 	// 2 2b
 	// 1 1c
 }
+
+// cancelDiff cancels its context after a number of calls to Equal.
+type cancelDiff struct {
+	lineDiff
+	calls  int
+	cancel func()
+}
+
+func (d *cancelDiff) Equal(i, j int) bool {
+	if d.calls--; d.calls == 0 {
+		d.cancel()
+	}
+	return d.lineDiff.Equal(i, j)
+}
+
+func TestDiffContext(t *testing.T) {
+	a, b := numbers(1000, nil), numbers(1000, nil)
+	for i := range b {
+		b[i] += "x"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &cancelDiff{lineDiff: lineDiff{a: a, b: b}, calls: 100, cancel: cancel}
+	if _, err := DiffContext(ctx, d); err != context.Canceled {
+		t.Errorf("want %v, have %v", context.Canceled, err)
+	}
+	if d.common != nil {
+		t.Errorf("Common called after cancellation: %v", d.common)
+	}
+
+	d = &cancelDiff{lineDiff: lineDiff{a: a[:10], b: a[:9]}}
+	edits, err := DiffContext(context.Background(), d)
+	if err != nil || edits != 1 {
+		t.Errorf("want 1 edit, have %d, %v", edits, err)
+	}
+}
+
+func TestDiffDeadline(t *testing.T) {
+	a, b := numbers(1000, nil), numbers(1000, nil)
+	for i := range b {
+		b[i] += "x"
+	}
+	d := &lineDiff{a: a, b: b}
+	if _, err := DiffDeadline(d, 0); err != context.DeadlineExceeded {
+		t.Errorf("want %v, have %v", context.DeadlineExceeded, err)
+	}
+	if d.common != nil {
+		t.Errorf("Common called after the deadline: %v", d.common)
+	}
+
+	d = &lineDiff{a: a[:10], b: a[:9]}
+	edits, err := DiffDeadline(d, time.Hour)
+	if err != nil || edits != 1 {
+		t.Errorf("want 1 edit, have %d, %v", edits, err)
+	}
+}
+
+func TestDiffBounded(t *testing.T) {
+	for i, test := range diffTests {
+		for _, max := range []int{test.edits - 1, test.edits, test.edits + 1} {
+			d := &stringDiff{a: test.a, b: test.b}
+			edits, ok := DiffBounded(d, max)
+			if max < test.edits {
+				if edits != max+1 || ok || d.lcsa != nil {
+					t.Errorf("test %d, max %d: want %d, false and no Common calls, have %d, %v, %q", i, max, max+1, edits, ok, d.lcsa)
+				}
+				continue
+			}
+			if edits != test.edits || !ok || !reflect.DeepEqual(d.lcsa, test.lcs) {
+				t.Errorf("test %d, max %d: want %d, true, %q, have %d, %v, %q", i, max, test.edits, test.lcs, edits, ok, d.lcsa)
+			}
+		}
+	}
+}
+
+func TestHashLines(t *testing.T) {
+	// Known FNV-1a values.
+	hs := HashLines([]string{"", "a", "foobar"})
+	want := []uint64{0xcbf29ce484222325, 0xaf63dc4c8601ec8c, 0x85944171f73967e8}
+	if !reflect.DeepEqual(hs, want) {
+		t.Errorf("want %x, have %x", want, hs)
+	}
+}
+
+func TestSideBySideHashCollisions(t *testing.T) {
+	// With all hashes colliding the result must be the same.
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"b", "x", "d", "e"}
+	d := &sideBySide{a: a, b: b, ha: make([]uint64, len(a)), hb: make([]uint64, len(b))}
+	Diff(d)
+	if want := SideBySide(a, b); !reflect.DeepEqual(d.lines, want) {
+		t.Errorf("want %v, have %v", want, d.lines)
+	}
+}
+
+func ExampleBlame() {
+	lines := Blame([][]string{
+		{"0a", "0b", "0c"},
+		{"1a", "0a", "1b", "0c", "1c"},
+		{"0a", "1b", "0c", "2a", "2b", "1c"},
+	})
+	for _, l := range lines {
+		fmt.Println(l.Version, l.Text)
+	}
+	// Output:
+	// 0 0a
+	// 1 1b
+	// 0 0c
+	// 2 2a
+	// 2 2b
+	// 1 1c
+}
+
+func TestAnnotateWithDeletions(t *testing.T) {
+	v0 := Annotate(nil, []string{"a", "b", "c", "d"}, 0)
+	v1 := Annotate(v0, []string{"a", "x", "c", "d"}, 1)
+	b := []string{"x", "d", "e"}
+	lines, dels := AnnotateWithDeletions(v1, b, 2)
+	if want := Annotate(v1, b, 2); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+	if want := []Deletion{{"a", 0, 2}, {"c", 0, 2}}; !reflect.DeepEqual(dels, want) {
+		t.Errorf("want %v\nhave %v", want, dels)
+	}
+	if _, dels := AnnotateWithDeletions(v0, []string{"a", "b", "c", "d"}, 1); dels != nil {
+		t.Errorf("no changes: want no deletions, have %v", dels)
+	}
+}
+
+func TestAnnotator(t *testing.T) {
+	files := [][][]string{
+		{{"0a", "0b"}, {"1a", "0a", "0b"}, {"1a", "0b", "2a"}},
+		{{"x"}, {"x", "y"}},
+		{{"p", "q"}, {"q", "p"}, {"p", "q", "r"}, {"r"}},
+	}
+	var a Annotator
+	for k, versions := range files {
+		a.Reset()
+		for v, b := range versions {
+			a.Add(b, v)
+		}
+		if want := Blame(versions); !reflect.DeepEqual(a.Lines(), want) {
+			t.Errorf("file %d:\nwant %v\nhave %v\n", k, want, a.Lines())
+		}
+	}
+}
+
+func TestAnnotatorTrackRevert(t *testing.T) {
+	versions := [][]string{{"a", "b"}, {"a"}, {"a", "b", "c"}, {"a", "c"}, {"a", "b", "c"}}
+	var candidates [][]int
+	a := Annotator{TrackRevert: func(text string, vs []int) int {
+		candidates = append(candidates, append([]int(nil), vs...))
+		return vs[0]
+	}}
+	for v, b := range versions {
+		a.Add(b, v)
+	}
+	want := []AnnotatedLine{{"a", 0}, {"b", 0}, {"c", 2}}
+	if !reflect.DeepEqual(a.Lines(), want) {
+		t.Errorf("want %v\nhave %v", want, a.Lines())
+	}
+	if want := [][]int{{0}, {0}}; !reflect.DeepEqual(candidates, want) {
+		t.Errorf("candidates:\nwant %v\nhave %v", want, candidates)
+	}
+
+	a.TrackRevert = func(text string, vs []int) int { return 10 + vs[len(vs)-1] }
+	a.Reset()
+	for v, b := range versions {
+		a.Add(b, v)
+	}
+	want = []AnnotatedLine{{"a", 0}, {"b", 20}, {"c", 2}} // b is recorded as 10 in version 2
+	if !reflect.DeepEqual(a.Lines(), want) {
+		t.Errorf("after Reset:\nwant %v\nhave %v", want, a.Lines())
+	}
+}
+
+func ExampleAnnotateWith() {
+	type change struct {
+		Author string
+		Commit string
+	}
+	files := [][]string{
+		{"0a", "0b", "0c"},
+		{"1a", "0a", "1b", "0c", "1c"},
+	}
+	lines := AnnotateWith(nil, files[0], change{"alice", "c0ffee"})
+	lines = AnnotateWith(lines, files[1], change{"bob", "f00d"})
+	for _, l := range lines {
+		fmt.Println(l.Meta.Author, l.Meta.Commit, l.Text)
+	}
+	// Output:
+	// bob f00d 1a
+	// alice c0ffee 0a
+	// bob f00d 1b
+	// alice c0ffee 0c
+	// bob f00d 1c
+}
+
+func TestSideBySideFunc(t *testing.T) {
+	a := []string{"if x {", "  y()", "}"}
+	b := []string{"if x {", "\ty()", "\tz()", "} "}
+	lines := SideBySideFunc(a, b, func(x, y string) bool {
+		return strings.TrimSpace(x) == strings.TrimSpace(y)
+	})
+	want := []SideBySideLine{
+		{Left: "if x {", Right: "if x {", Type: NoChange},
+		{Left: "  y()", Right: "\ty()", Type: NoChange},
+		{Right: "\tz()", Type: Added},
+		{Left: "}", Right: "} ", Type: NoChange},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+}
+
+func TestSideBySideFold(t *testing.T) {
+	a := []string{"SELECT *", "FROM t", "WHERE x = 1"}
+	b := []string{"select *", "from T", "where y = 1"}
+	want := []SideBySideLine{
+		{Left: "SELECT *", Right: "select *", Type: NoChange},
+		{Left: "FROM t", Right: "from T", Type: NoChange},
+		{Left: "WHERE x = 1", Right: "where y = 1", Type: Changed},
+	}
+	if lines := SideBySideFold(a, b); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+	if !EqualFold("Straße", "STRAßE") || EqualFold("a", "b") {
+		t.Error("EqualFold")
+	}
+}
+
+func TestChangeTypeString(t *testing.T) {
+	for typ, want := range map[ChangeType]string{NoChange: "NoChange", Moved: "Moved", NearMatch: "NearMatch", -1: "ChangeType(-1)", 99: "ChangeType(99)"} {
+		if have := typ.String(); have != want {
+			t.Errorf("want %s, have %s", want, have)
+		}
+	}
+	if have := fmt.Sprint(SideBySideLine{Left: "a", Type: Deleted}); have != "{a  Deleted 0 0 [] [] false}" {
+		t.Errorf("have %s", have)
+	}
+}
+
+func TestSideBySideN(t *testing.T) {
+	for i, test := range diffTests {
+		a, b := strings.Split(test.a, ""), strings.Split(test.b, "")
+		lines, edits := SideBySideN(a, b)
+		if edits != test.edits {
+			t.Errorf("test %d: want %d edits, have %d", i, test.edits, edits)
+		}
+		if want := SideBySide(a, b); !reflect.DeepEqual(lines, want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, want, lines)
+		}
+	}
+}
+
+func TestSideBySideNormalizeEOL(t *testing.T) {
+	a := []string{"a\r", "b\r", "c\r"}
+	b := []string{"a", "x", "c"}
+	want := []SideBySideLine{
+		{Left: "a\r", Right: "a", Type: NoChange},
+		{Left: "b\r", Right: "x", Type: Changed},
+		{Left: "c\r", Right: "c", Type: NoChange},
+	}
+	if lines := SideBySideNormalizeEOL(a, b); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+}
+
+func TestSideBySideNear(t *testing.T) {
+	a := []string{"a", "B", "c", "d"}
+	b := []string{"a", "b", "x", "D"}
+	want := []SideBySideLine{
+		{Left: "a", Right: "a", Type: NoChange},
+		{Left: "B", Right: "b", Type: NearMatch},
+		{Left: "c", Right: "x", Type: Changed},
+		{Left: "d", Right: "D", Type: NearMatch},
+	}
+	strict := func(x, y string) bool { return x == y }
+	lines := SideBySideNear(a, b, EqualFold, strict)
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+	if _, _, _, unchanged := SideBySideStats(lines); unchanged != 3 {
+		t.Errorf("want 3 unchanged lines, have %d", unchanged)
+	}
+}
+
+func TestSideBySideOpts(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "y", "y", "d"}
+	want := []SideBySideLine{
+		{Left: "a", Right: "a", Type: NoChange},
+		{Left: "b", Type: Deleted},
+		{Left: "c", Type: Deleted},
+		{Right: "x", Type: Added},
+		{Right: "y", Type: Added},
+		{Right: "y", Type: Added},
+		{Left: "d", Right: "d", Type: NoChange},
+	}
+	if lines := SideBySideOpts(a, b, SeparateAddDelete); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+	if lines := SideBySideOpts(a, b, PairChanged); !reflect.DeepEqual(lines, SideBySide(a, b)) {
+		t.Errorf("want %v\nhave %v", SideBySide(a, b), lines)
+	}
+}
+
+func TestSideBySidePairLimit(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "y", "y", "d"}
+	want := []SideBySideLine{
+		{Left: "a", Right: "a", Type: NoChange},
+		{Left: "b", Right: "x", Type: Changed},
+		{Left: "c", Type: Deleted},
+		{Right: "y", Type: Added},
+		{Right: "y", Type: Added},
+		{Left: "d", Right: "d", Type: NoChange},
+	}
+	if lines := SideBySidePairLimit(a, b, 1); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+	if lines := SideBySidePairLimit(a, b, math.MaxInt); !reflect.DeepEqual(lines, SideBySide(a, b)) {
+		t.Errorf("want %v\nhave %v", SideBySide(a, b), lines)
+	}
+	if lines, want := SideBySidePairLimit(a, b, 0), SideBySideOpts(a, b, SeparateAddDelete); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+}
+
+func TestSideBySideStream(t *testing.T) {
+	a := []string{"a", "b", "c"}
+	b := []string{"a", "x", "c", "d", "e"}
+	var lines []SideBySideLine
+	err := SideBySideStream(a, b, func(l SideBySideLine) error {
+		lines = append(lines, l)
+		return nil
+	})
+	if want := SideBySide(a, b); err != nil || !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v, %v", want, lines, err)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = SideBySideStream(a, b, func(l SideBySideLine) error {
+		if n++; n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("want error %v after 2 lines, have %v after %d", stop, err, n)
+	}
+}
+
+// badDiff claims lengths that differ from those of its sequences.
+type badDiff struct {
+	lineDiff
+	n, m int
+}
+
+func (d *badDiff) Lengths() (int, int) { return d.n, d.m }
+
+func TestDiffSafe(t *testing.T) {
+	d := &badDiff{lineDiff{a: []string{"a", "b"}, b: []string{"a", "b"}}, 3, 3}
+	_, err := DiffSafe(d)
+	var re runtime.Error
+	if !errors.As(err, &re) || err.Error() != "diff: runtime error: index out of range [2] with length 2" {
+		t.Errorf("want wrapped runtime error, have %v", err)
+	}
+
+	for i, test := range diffTests {
+		edits, err := DiffSafe(&stringDiff{a: test.a, b: test.b})
+		if err != nil || edits != test.edits {
+			t.Errorf("test %d: want %d edits, have %d, %v", i, test.edits, edits, err)
+		}
+	}
+}
+
+func TestDiffNegativeLength(t *testing.T) {
+	for _, l := range [][2]int{{-1, 0}, {0, -1}, {-5, 3}} {
+		d := &badDiff{n: l[0], m: l[1]}
+		if _, err := DiffSafe(d); err != ErrNegativeLength {
+			t.Errorf("lengths %v: want %v, have %v", l, ErrNegativeLength, err)
+		}
+		if d.common != nil {
+			t.Errorf("lengths %v: Common called: %v", l, d.common)
+		}
+	}
+}
+
+func TestDiffTooLarge(t *testing.T) {
+	for _, l := range [][2]int{{maxLength, 1}, {1, maxLength}, {math.MaxInt, math.MaxInt}} {
+		d := &badDiff{n: l[0], m: l[1]}
+		if _, err := DiffSafe(d); err != ErrTooLarge {
+			t.Errorf("lengths %v: want %v, have %v", l, ErrTooLarge, err)
+		}
+		for name, fn := range map[string]func(Interface) int{"DiffLinear": DiffLinear, "DiffPatience": DiffPatience, "DiffHistogram": DiffHistogram} {
+			func() {
+				defer func() {
+					if r := recover(); r != ErrTooLarge {
+						t.Errorf("%s, lengths %v: want panic %v, have %v", name, l, ErrTooLarge, r)
+					}
+				}()
+				fn(d)
+			}()
+		}
+		if d.common != nil {
+			t.Errorf("lengths %v: Common called: %v", l, d.common)
+		}
+	}
+}
+
+// TestDiffBacktraceEqual checks that Diff makes no more calls to Equal than
+// the forward search does on its own in DiffDistance, so that tracing the
+// path back makes none.
+func TestDiffBacktraceEqual(t *testing.T) {
+	check := func(name string, a, b string) {
+		_, calls := DiffMetered(&stringDiff{a: a, b: b})
+		search := &meter{Interface: &stringDiff{a: a, b: b}, max: math.MaxInt}
+		DiffDistance(search)
+		if calls != search.calls {
+			t.Errorf("%s: Diff calls Equal %d times, the search alone %d times", name, calls, search.calls)
+		}
+	}
+	for i, test := range diffTests {
+		check(fmt.Sprintf("test %d", i), test.a, test.b)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		check(fmt.Sprintf("random %d", i), randString(r, 30, "abc"), randString(r, 30, "abc"))
+	}
+}
+
+// TestDiffDissimilar diffs sequences with nothing in common, for which the
+// backtrace goes through the largest possible number of snakes.
+func TestDiffDissimilar(t *testing.T) {
+	// With nothing in common, the path has 2*n edits. Tracing it back must
+	// not take stack space in proportion, which the limit would not allow.
+	defer debug.SetMaxStack(debug.SetMaxStack(64 << 10))
+	const n = 1000
+	a, b := make([]string, n), make([]string, n)
+	for i := range a {
+		a[i], b[i] = "a", "b"
+	}
+	d := &lineDiff{a: a, b: b}
+	if have := Diff(d); have != 2*n {
+		t.Errorf("want %d edits, have %d", 2*n, have)
+	}
+	if want := [][3]int{{n, n, 0}}; !reflect.DeepEqual(d.common, want) {
+		t.Errorf("want %v\nhave %v", want, d.common)
+	}
+}
+
+func TestDiffFast(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"", ""}, {"", "abc"}, {"abc", ""}, {"abc", "abc"},
+		{"ab", "abab"}, {"abab", "ab"}, {"a", "baa"}, {"ab", "xaby"},
+	}
+	for _, test := range diffTests {
+		tests = append(tests, struct{ a, b string }{test.a, test.b})
+	}
+	for i, test := range tests {
+		want := &lineDiff{a: strings.Split(test.a, ""), b: strings.Split(test.b, "")}
+		have := &lineDiff{a: want.a, b: want.b}
+		if w, h := Diff(want), DiffFast(have); h != w {
+			t.Errorf("test %d: want %d edits, have %d", i, w, h)
+		}
+		if !reflect.DeepEqual(have.common, want.common) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, want.common, have.common)
+		}
+	}
+}
+
+func TestDiffProgress(t *testing.T) {
+	for i, test := range diffTests {
+		var ds []int
+		edits := DiffProgress(&stringDiff{a: test.a, b: test.b}, func(d, max int) {
+			if want := len(test.a) + len(test.b); max != want {
+				t.Errorf("test %d: want max %d, have %d", i, want, max)
+			}
+			ds = append(ds, d)
+		})
+		if edits != test.edits {
+			t.Errorf("test %d: want %d edits, have %d", i, test.edits, edits)
+		}
+		var want []int
+		if test.a != "" && test.b != "" {
+			for d := 0; d <= edits; d++ {
+				want = append(want, d)
+			}
+		}
+		if !reflect.DeepEqual(ds, want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, want, ds)
+		}
+	}
+}
+
+func TestDiffDistance(t *testing.T) {
+	for i, test := range diffTests {
+		if have := DiffDistance(&stringDiff{a: test.a, b: test.b}); have != test.edits {
+			t.Errorf("test %d: want %d edits, have %d", i, test.edits, have)
+		}
+	}
+}
+
+func TestDiffReverseOrder(t *testing.T) {
+	for i, test := range diffTests {
+		fwd := &lineDiff{a: strings.Split(test.a, ""), b: strings.Split(test.b, "")}
+		rev := &lineDiff{a: fwd.a, b: fwd.b}
+		if want, have := Diff(fwd), DiffReverseOrder(rev); have != want {
+			t.Errorf("test %d: want %d edits, have %d", i, want, have)
+		}
+		want := make([][3]int, len(fwd.common))
+		for k, c := range fwd.common {
+			want[len(want)-1-k] = c
+		}
+		if !reflect.DeepEqual(rev.common, want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, want, rev.common)
+		}
+	}
+}
+
+func TestDiffPrefixSuffix(t *testing.T) {
+	var tests = []struct {
+		a, b   string
+		common [][3]int
+	}{
+		{"abc", "abc", [][3]int{{0, 0, 3}}},
+		{"abxc", "abc", [][3]int{{0, 0, 2}, {3, 2, 1}}},
+		// The common suffix "a" is aligned with the first "a" of "baa",
+		// as it would be without looking for the suffix first.
+		{"a", "baa", [][3]int{{0, 1, 1}, {1, 3, 0}}},
+		{"ab", "bab", [][3]int{{0, 1, 2}}},
+	}
+	for i, test := range tests {
+		d := &lineDiff{a: strings.Split(test.a, ""), b: strings.Split(test.b, "")}
+		Diff(d)
+		if !reflect.DeepEqual(d.common, test.common) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.common, d.common)
+		}
+	}
+}
+
+func TestDiffOrBail(t *testing.T) {
+	var tests = []struct {
+		a, b  string
+		ratio float64
+		edits int
+		ok    bool
+	}{
+		{"", "", 1, 0, true},
+		{"abc", "abd", 1, 2, true},
+		{"abcd", "ab", 2, 2, true},
+		{"abcde", "ab", 2, 0, false},
+		{"ab", "abcde", 2, 0, false},
+		{"", "a", 100, 0, false},
+		{"", "a", math.Inf(1), 1, true},
+	}
+	for i, test := range tests {
+		edits, ok := DiffOrBail(&stringDiff{a: test.a, b: test.b}, test.ratio)
+		if edits != test.edits || ok != test.ok {
+			t.Errorf("test %d: want %d, %v, have %d, %v", i, test.edits, test.ok, edits, ok)
+		}
+	}
+}
+
+func TestDiffLimited(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		maxCells int
+		edits    int
+		err      error
+	}{
+		{"abc", "abd", 28, 2, nil},
+		{"abc", "abd", 27, 0, ErrTooLarge},
+		{"abc", "", 0, 3, nil},
+		{"", "", -1, 0, nil},
+	}
+	for i, test := range tests {
+		d := &stringDiff{a: test.a, b: test.b}
+		edits, err := DiffLimited(d, test.maxCells)
+		if edits != test.edits || err != test.err {
+			t.Errorf("test %d: want %d, %v, have %d, %v", i, test.edits, test.err, edits, err)
+		}
+	}
+	if _, err := DiffLimited(&badDiff{n: math.MaxInt / 2, m: math.MaxInt / 2}, math.MaxInt); err != ErrTooLarge {
+		t.Errorf("huge lengths: want %v, have %v", ErrTooLarge, err)
+	}
+}
@@ -0,0 +1,23 @@
+package diff
+
+// Repeated diffs
+
+// A Differ diffs changing versions of a file against the same base, hashing
+// the lines of the base only once. Its methods may be called concurrently.
+type Differ struct {
+	base   []string
+	hashes []uint64
+}
+
+// NewDiffer returns a Differ for base. base must not be modified while the
+// Differ is in use.
+func NewDiffer(base []string) *Differ {
+	return &Differ{base, HashLines(base)}
+}
+
+// Against returns the same result as SideBySide(base, current).
+func (d *Differ) Against(current []string) []SideBySideLine {
+	s := &sideBySide{a: d.base, b: current, ha: d.hashes, hb: HashLines(current)}
+	Diff(s)
+	return s.lines
+}
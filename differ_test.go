@@ -0,0 +1,30 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDiffer(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	base := strings.Split(randString(r, 20, "abc"), "")
+	versions := make([][]string, 100)
+	for k := range versions {
+		versions[k] = strings.Split(randString(r, 20, "abc"), "")
+	}
+	d := NewDiffer(base)
+	var wg sync.WaitGroup
+	for _, v := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if have, want := d.Against(v), SideBySide(base, v); !reflect.DeepEqual(have, want) {
+				t.Errorf("%q, %q:\nwant %v\nhave %v\n", base, v, want, have)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,197 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// Edit script
+
+// An Op is one contiguous run of an edit script from a left to a right
+// sequence.
+//
+// For Kind==NoChange, left[FromI:FromI+Len] == right[FromJ:FromJ+Len]. For
+// Kind==Deleted, left[FromI:FromI+Len] is removed. For Kind==Added,
+// right[FromJ:FromJ+Len] is inserted. FromI and FromJ are always the positions
+// in left and right at which the op applies, so the ops of an edit script
+// cover both sequences from top to bottom without gaps.
+//
+// Lines optionally holds the elements the op covers, taken from right for
+// Kind==Added and from left otherwise. It is needed to Apply Added ops.
+type Op struct {
+	Kind  int // NoChange, Added or Deleted
+	FromI int
+	FromJ int
+	Len   int
+	Lines []string
+}
+
+// EditScript computes the edit script that turns the left into the right
+// sequence of data. Within each run of changes the Deleted op precedes the
+// Added op; ops of length zero are omitted. data.Common is not called.
+func EditScript(data Interface) []Op {
+	d := &editScript{Interface: data}
+	Diff(d)
+	return d.ops
+}
+
+// EditScriptLines returns the EditScript from a to b with the Lines of all ops
+// filled in.
+func EditScriptLines(a, b []string) []Op {
+	ops := EditScript(lineData{a, b})
+	for k, op := range ops {
+		if op.Kind == Added {
+			ops[k].Lines = b[op.FromJ : op.FromJ+op.Len]
+		} else {
+			ops[k].Lines = a[op.FromI : op.FromI+op.Len]
+		}
+	}
+	return ops
+}
+
+type editScript struct {
+	Interface
+	i   int
+	j   int
+	ops []Op
+}
+
+func (d *editScript) Common(i, j, n int) {
+	if i > d.i {
+		d.ops = append(d.ops, Op{Deleted, d.i, d.j, i - d.i, nil})
+	}
+	if j > d.j {
+		d.ops = append(d.ops, Op{Added, i, d.j, j - d.j, nil})
+	}
+	if n > 0 {
+		d.ops = append(d.ops, Op{NoChange, i, j, n, nil})
+	}
+	d.i, d.j = i+n, j+n
+}
+
+// A Change is an op of an edit script without its lines: left[I:I+N] and
+// right[J:J+N] are equal if Kind==NoChange, left[I:I+N] is deleted if
+// Kind==Deleted, and right[J:J+N] is added if Kind==Added.
+type Change struct {
+	Kind int // NoChange, Added or Deleted
+	I    int
+	J    int
+	N    int
+}
+
+// Changes returns an iterator over the changes of the EditScript of data.
+// The diff is computed before the first change is yielded; stopping the
+// iteration early only saves reporting the rest. data.Common is not called.
+func Changes(data Interface) iter.Seq[Change] {
+	return func(yield func(Change) bool) {
+		d := &changeSeq{Interface: data, yield: yield}
+		Diff(d)
+	}
+}
+
+type changeSeq struct {
+	Interface
+	i, j  int
+	yield func(Change) bool
+	done  bool // yield returned false
+}
+
+func (d *changeSeq) Common(i, j, n int) {
+	for _, c := range []Change{{Deleted, d.i, d.j, i - d.i}, {Added, i, d.j, j - d.j}, {NoChange, i, j, n}} {
+		if !d.done && c.N > 0 {
+			d.done = !d.yield(c)
+		}
+	}
+	d.i, d.j = i+n, j+n
+}
+
+// Apply applies the edit script ops to a and returns the result. The ops must
+// cover a from top to bottom as those returned by EditScript do, and Added ops
+// must carry their Lines. Where NoChange and Deleted ops carry Lines, they
+// must match a.
+func Apply(a []string, ops []Op) ([]string, error) {
+	var b []string
+	i := 0
+	for k, op := range ops {
+		if op.FromI != i || op.FromJ != len(b) {
+			return nil, fmt.Errorf("diff: op %d at (%d, %d), want (%d, %d)", k, op.FromI, op.FromJ, i, len(b))
+		}
+		if op.Len < 0 {
+			return nil, fmt.Errorf("diff: op %d has negative length %d", k, op.Len)
+		}
+		switch op.Kind {
+		case NoChange, Deleted:
+			if op.FromI+op.Len > len(a) {
+				return nil, fmt.Errorf("diff: op %d covers lines %d to %d of %d", k, op.FromI, op.FromI+op.Len, len(a))
+			}
+			if op.Lines != nil && !equalLines(op.Lines, a[op.FromI:op.FromI+op.Len]) {
+				return nil, fmt.Errorf("diff: op %d does not match lines %d to %d", k, op.FromI, op.FromI+op.Len)
+			}
+			if op.Kind == NoChange {
+				b = append(b, a[op.FromI:op.FromI+op.Len]...)
+			}
+			i += op.Len
+		case Added:
+			if len(op.Lines) != op.Len {
+				return nil, fmt.Errorf("diff: op %d adds %d lines but carries %d", k, op.Len, len(op.Lines))
+			}
+			b = append(b, op.Lines...)
+		default:
+			return nil, fmt.Errorf("diff: op %d has invalid kind %d", k, op.Kind)
+		}
+	}
+	if i != len(a) {
+		return nil, errors.New("diff: ops do not cover all lines")
+	}
+	return b, nil
+}
+
+// Reverse returns the edit script from right to left for the edit script ops
+// from left to right: Added and Deleted ops are swapped, as are FromI and
+// FromJ. Deleted ops are moved before Added ops within each run of changes,
+// so reversing the result gives back ops.
+func Reverse(ops []Op) []Op {
+	var rev []Op
+	for k := 0; k < len(ops); {
+		if ops[k].Kind == NoChange {
+			op := ops[k]
+			op.FromI, op.FromJ = op.FromJ, op.FromI
+			rev = append(rev, op)
+			k++
+			continue
+		}
+		end := k
+		for end < len(ops) && ops[end].Kind != NoChange {
+			end++
+		}
+		i, j := ops[k].FromJ, ops[k].FromI // start of the run in the reversed script
+		n := 0                             // elements deleted from right
+		for _, op := range ops[k:end] {
+			if op.Kind == Added {
+				rev = append(rev, Op{Deleted, i + n, j, op.Len, op.Lines})
+				n += op.Len
+			}
+		}
+		m := 0 // elements added from left
+		for _, op := range ops[k:end] {
+			if op.Kind != Added {
+				rev = append(rev, Op{Added, i + n, j + m, op.Len, op.Lines})
+				m += op.Len
+			}
+		}
+		k = end
+	}
+	return rev
+}
+
+// lineData is the Interface of two slices of lines for callers that are only
+// interested in the result of Diff or EditScript.
+type lineData struct {
+	a []string
+	b []string
+}
+
+func (d lineData) Lengths() (int, int) { return len(d.a), len(d.b) }
+func (d lineData) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d lineData) Common(i, j, n int)  {}
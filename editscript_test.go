@@ -0,0 +1,135 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditScript(t *testing.T) {
+	var tests = []struct {
+		a   string
+		b   string
+		ops []Op
+	}{
+		{"", "", nil},
+		{"", "ab", []Op{{Added, 0, 0, 2, nil}}},
+		{"ab", "", []Op{{Deleted, 0, 0, 2, nil}}},
+		{"abc", "abc", []Op{{NoChange, 0, 0, 3, nil}}},
+		{"abc", "ac", []Op{{NoChange, 0, 0, 1, nil}, {Deleted, 1, 1, 1, nil}, {NoChange, 2, 1, 1, nil}}},
+		{"bc", "abc", []Op{{Added, 0, 0, 1, nil}, {NoChange, 0, 1, 2, nil}}},
+		{"axc", "ayc", []Op{{NoChange, 0, 0, 1, nil}, {Deleted, 1, 1, 1, nil}, {Added, 2, 1, 1, nil}, {NoChange, 2, 2, 1, nil}}},
+	}
+	for i, test := range tests {
+		ops := EditScript(&stringDiff{a: test.a, b: test.b})
+		if !reflect.DeepEqual(ops, test.ops) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.ops, ops)
+		}
+	}
+}
+
+func TestEditScriptCoverage(t *testing.T) {
+	for i, test := range diffTests {
+		ops := EditScript(&stringDiff{a: test.a, b: test.b})
+		x, y, edits := 0, 0, 0
+		for _, op := range ops {
+			if op.FromI != x || op.FromJ != y {
+				t.Fatalf("test %d: op %v does not start at (%d, %d)", i, op, x, y)
+			}
+			switch op.Kind {
+			case NoChange:
+				if test.a[x:x+op.Len] != test.b[y:y+op.Len] {
+					t.Fatalf("test %d: op %v is not common", i, op)
+				}
+				x += op.Len
+				y += op.Len
+			case Deleted:
+				x += op.Len
+				edits += op.Len
+			case Added:
+				y += op.Len
+				edits += op.Len
+			}
+		}
+		if x != len(test.a) || y != len(test.b) || edits != test.edits {
+			t.Errorf("test %d: ops end at (%d, %d) with %d edits, want (%d, %d) with %d",
+				i, x, y, edits, len(test.a), len(test.b), test.edits)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 10, "abc"), "")
+		b := strings.Split(randString(r, 10, "abc"), "")
+		have, err := Apply(a, EditScriptLines(a, b))
+		if err != nil || !equalLines(have, b) {
+			t.Fatalf("Apply(%q, EditScriptLines(%q, %q)) = %q, %v", a, a, b, have, err)
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	a := []string{"a", "b"}
+	var tests = []struct {
+		ops []Op
+		err string
+	}{
+		{nil, "diff: ops do not cover all lines"},
+		{[]Op{{NoChange, 0, 0, 3, nil}}, "diff: op 0 covers lines 0 to 3 of 2"},
+		{[]Op{{Deleted, 0, 0, 1, nil}, {Deleted, 1, 0, 2, nil}}, "diff: op 1 covers lines 1 to 3 of 2"},
+		{[]Op{{Deleted, 0, 0, 2, []string{"a", "c"}}}, "diff: op 0 does not match lines 0 to 2"},
+		{[]Op{{NoChange, 1, 0, 1, nil}}, "diff: op 0 at (1, 0), want (0, 0)"},
+		{[]Op{{Added, 0, 0, 1, nil}}, "diff: op 0 adds 1 lines but carries 0"},
+		{[]Op{{Changed, 0, 0, 2, nil}}, "diff: op 0 has invalid kind 3"},
+	}
+	for i, test := range tests {
+		if _, err := Apply(a, test.ops); err == nil || err.Error() != test.err {
+			t.Errorf("test %d: want error %q, have %v", i, test.err, err)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 10, "abc"), "")
+		b := strings.Split(randString(r, 10, "abc"), "")
+		ops := EditScriptLines(a, b)
+		rev := Reverse(ops)
+		have, err := Apply(b, rev)
+		if err != nil || !equalLines(have, a) {
+			t.Fatalf("Apply(%q, Reverse(EditScriptLines(%q, %q))) = %q, %v", b, a, b, have, err)
+		}
+		if twice := Reverse(rev); !reflect.DeepEqual(twice, ops) {
+			t.Fatalf("%q, %q: reversing twice:\nwant %v\nhave %v", a, b, ops, twice)
+		}
+	}
+}
+
+func TestChanges(t *testing.T) {
+	for i, test := range diffTests {
+		var want, have []Change
+		for _, op := range EditScript(&stringDiff{a: test.a, b: test.b}) {
+			want = append(want, Change{op.Kind, op.FromI, op.FromJ, op.Len})
+		}
+		for c := range Changes(&stringDiff{a: test.a, b: test.b}) {
+			have = append(have, c)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, want, have)
+		}
+	}
+
+	n := 0
+	for range Changes(&stringDiff{a: "axbxcxd", b: "aybycyd"}) {
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("want 2 changes before break, have %d", n)
+	}
+}
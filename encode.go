@@ -0,0 +1,174 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Binary encoding
+
+// The encoding of an edit script starts with encodingMagic and the version
+// encodingVersion.
+const (
+	encodingMagic   = 0xd1
+	encodingVersion = 1
+)
+
+// ErrBadEncoding is returned by Decode for input that is not an edit script
+// written by Encode.
+var ErrBadEncoding = errors.New("diff: malformed encoded edit script")
+
+// Encode writes the edit script ops to w in a compact binary form that Decode
+// reads back. The format, in version 1, is:
+//
+//	magic byte 0xd1, version byte 1
+//	number of ops, uvarint
+//	for each op:
+//		Kind, uvarint
+//		FromI and FromJ, varints relative to where the op before them
+//		ends (0, 0 for the first op), so 0 for ops without gaps
+//		Len, uvarint
+//		number of Lines plus 1, or 0 if Lines is nil, uvarint
+//		each line as its length in bytes, uvarint, and its bytes
+//
+// Nil and empty Lines are told apart and both decoded as they were. Encode
+// returns an error for an op with an invalid Kind or a negative Len.
+func Encode(ops []Op, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var buf [binary.MaxVarintLen64]byte
+	uvarint := func(x uint64) { bw.Write(buf[:binary.PutUvarint(buf[:], x)]) }
+	varint := func(x int64) { bw.Write(buf[:binary.PutVarint(buf[:], x)]) }
+
+	bw.Write([]byte{encodingMagic, encodingVersion})
+	uvarint(uint64(len(ops)))
+	i, j := 0, 0
+	for k, op := range ops {
+		if op.Kind != NoChange && op.Kind != Added && op.Kind != Deleted {
+			return fmt.Errorf("diff: op %d has invalid kind %d", k, op.Kind)
+		}
+		if op.Len < 0 {
+			return fmt.Errorf("diff: op %d has negative length %d", k, op.Len)
+		}
+		uvarint(uint64(op.Kind))
+		varint(int64(op.FromI - i))
+		varint(int64(op.FromJ - j))
+		uvarint(uint64(op.Len))
+		if op.Lines == nil {
+			uvarint(0)
+		} else {
+			uvarint(uint64(len(op.Lines)) + 1)
+		}
+		for _, l := range op.Lines {
+			uvarint(uint64(len(l)))
+			bw.WriteString(l)
+		}
+		i, j = opEnd(op)
+	}
+	return bw.Flush()
+}
+
+// Decode reads an edit script written by Encode from r. It returns
+// ErrBadEncoding if the input is malformed or ends early, and an error naming
+// the version for input written in a version it does not know. If r is not
+// an io.ByteReader, Decode may read past the end of the edit script.
+func Decode(r io.Reader) ([]Op, error) {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	var err error
+	uvarint := func() int {
+		if err != nil {
+			return 0
+		}
+		var x uint64
+		if x, err = binary.ReadUvarint(br); err == nil && x > 1<<62 {
+			err = ErrBadEncoding
+		}
+		return int(x)
+	}
+	varint := func() int {
+		if err != nil {
+			return 0
+		}
+		var x int64
+		if x, err = binary.ReadVarint(br); err == nil && (x > 1<<62 || x < -1<<62) {
+			err = ErrBadEncoding
+		}
+		return int(x)
+	}
+	readByte := func() byte {
+		if err != nil {
+			return 0
+		}
+		var b byte
+		b, err = br.ReadByte()
+		return b
+	}
+
+	if readByte() != encodingMagic {
+		return nil, badEncoding(err)
+	}
+	if v := readByte(); v != encodingVersion {
+		if err != nil {
+			return nil, badEncoding(err)
+		}
+		return nil, fmt.Errorf("diff: unsupported edit script encoding version %d", v)
+	}
+	var ops []Op
+	i, j := 0, 0
+	for k, n := 0, uvarint(); k < n && err == nil; k++ {
+		op := Op{Kind: uvarint()}
+		op.FromI, op.FromJ = i+varint(), j+varint()
+		op.Len = uvarint()
+		if lines := uvarint(); lines > 0 {
+			op.Lines = make([]string, 0, min(lines-1, 1024))
+			for ; lines > 1 && err == nil; lines-- {
+				var l strings.Builder
+				if size := uvarint(); err == nil {
+					_, err = io.CopyN(&l, br, int64(size))
+				}
+				op.Lines = append(op.Lines, l.String())
+			}
+		}
+		if err == nil && op.Kind != NoChange && op.Kind != Added && op.Kind != Deleted {
+			err = ErrBadEncoding
+		}
+		ops = append(ops, op)
+		i, j = opEnd(op)
+	}
+	if err != nil {
+		return nil, badEncoding(err)
+	}
+	return ops, nil
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// badEncoding returns the error Decode returns for err, which is
+// ErrBadEncoding for input that ends early.
+func badEncoding(err error) error {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrBadEncoding
+	}
+	return err
+}
+
+// opEnd returns the positions in the left and right sequence after op.
+func opEnd(op Op) (i, j int) {
+	i, j = op.FromI, op.FromJ
+	if op.Kind != Added {
+		i += op.Len
+	}
+	if op.Kind != Deleted {
+		j += op.Len
+	}
+	return i, j
+}
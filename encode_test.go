@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEncode(t *testing.T) {
+	for i, test := range diffTests {
+		a, b := strings.Split(test.a, ""), strings.Split(test.b, "")
+		for _, ops := range [][]Op{EditScript(lineData{a, b}), EditScriptLines(a, b)} {
+			var buf bytes.Buffer
+			if err := Encode(ops, &buf); err != nil {
+				t.Fatalf("test %d: %v", i, err)
+			}
+			decoded, err := Decode(iotest.OneByteReader(bytes.NewReader(buf.Bytes())))
+			if err != nil || !reflect.DeepEqual(decoded, ops) {
+				t.Errorf("test %d:\nwant %v\nhave %v, %v\n", i, ops, decoded, err)
+			}
+			for n := 0; n < buf.Len(); n++ {
+				if _, err := Decode(bytes.NewReader(buf.Bytes()[:n])); err != ErrBadEncoding {
+					t.Errorf("test %d, %d of %d bytes: want %v, have %v", i, n, buf.Len(), ErrBadEncoding, err)
+				}
+			}
+		}
+	}
+
+	// An edit script decoded can be applied.
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "c", "d", "e"}
+	var buf bytes.Buffer
+	if err := Encode(EditScriptLines(a, b), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xd1, 1, 5}; !bytes.HasPrefix(buf.Bytes(), want) {
+		t.Errorf("want prefix %v, have %v", want, buf.Bytes())
+	}
+	ops, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := Apply(a, ops); err != nil || !reflect.DeepEqual(c, b) {
+		t.Errorf("want %q, have %q, %v", b, c, err)
+	}
+
+	// Ops need not cover the sequences without gaps, and nil and empty Lines
+	// are kept apart.
+	ops = []Op{{Added, 5, 7, 1, []string{""}}, {NoChange, 2, 3, 2, nil}, {Deleted, 4, 5, 0, []string{}}}
+	buf.Reset()
+	if err := Encode(ops, &buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil || !reflect.DeepEqual(decoded, ops) {
+		t.Errorf("want %v\nhave %v, %v", ops, decoded, err)
+	}
+	if len(decoded) == 3 && (decoded[1].Lines != nil || decoded[2].Lines == nil) {
+		t.Errorf("want nil and empty Lines, have %#v and %#v", decoded[1].Lines, decoded[2].Lines)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	for i, ops := range [][]Op{{{Kind: Changed}}, {{Kind: Added, Len: -1}}} {
+		if err := Encode(ops, &bytes.Buffer{}); err == nil {
+			t.Errorf("test %d: no error", i)
+		}
+	}
+	for i, data := range []string{"", "x\x01\x00", "\xd1", "\xd1\x01\x01\x03\x00\x00\x00\x00"} {
+		if _, err := Decode(strings.NewReader(data)); err != ErrBadEncoding {
+			t.Errorf("test %d: want %v, have %v", i, ErrBadEncoding, err)
+		}
+	}
+	const want = "diff: unsupported edit script encoding version 2"
+	if _, err := Decode(strings.NewReader("\xd1\x02\x00")); err == nil || err.Error() != want {
+		t.Errorf("want %q, have %v", want, err)
+	}
+}
@@ -0,0 +1,45 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func FuzzDiff(f *testing.F) {
+	for _, test := range diffTests {
+		f.Add([]byte(test.a), []byte(test.b))
+	}
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		if len(a) > 1000 || len(b) > 1000 {
+			t.Skip("too long for the quadratic algorithms")
+		}
+		for name, fn := range map[string]func(Interface) int{
+			"Diff":          Diff,
+			"DiffLinear":    DiffLinear,
+			"DiffPatience":  DiffPatience,
+			"DiffHistogram": DiffHistogram,
+		} {
+			d := &lineDiff{a: byteLines(a), b: byteLines(b)}
+			edits := fn(d)
+			t.Log(name) // shown if checkCommon fails
+			checkCommon(t, d, edits)
+		}
+		d := &lineDiff{a: byteLines(a), b: byteLines(b)}
+		if want, have := Diff(d), DiffDistance(d); have != want {
+			t.Errorf("DiffDistance: want %d, have %d", want, have)
+		}
+		fast := &lineDiff{a: d.a, b: d.b}
+		if DiffFast(fast); !reflect.DeepEqual(fast.common, d.common) {
+			t.Errorf("DiffFast:\nwant %v\nhave %v", d.common, fast.common)
+		}
+	})
+}
+
+// byteLines returns the bytes of p as lines of one byte each.
+func byteLines(p []byte) []string {
+	lines := make([]string, len(p))
+	for i := range p {
+		lines[i] = string(p[i : i+1])
+	}
+	return lines
+}
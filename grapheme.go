@@ -0,0 +1,132 @@
+package diff
+
+import "unicode"
+
+// Grapheme cluster diff
+
+// GraphemeDiff is like RuneDiff but treats the strings as sequences of
+// grapheme clusters, the characters a reader sees, so that a letter and its
+// combining marks, an emoji with its modifiers or a ZWJ sequence such as a
+// family emoji are added, deleted or kept as a whole. The indices and lengths
+// of the ops count clusters, and the Lines of each op hold its clusters.
+//
+// The clusters are those of the extended grapheme clusters of Unicode
+// Standard Annex #29, except that prepended concatenation marks are not
+// joined to what follows them and that extended pictographic characters are
+// approximated by the blocks of symbols and emoji.
+func GraphemeDiff(a, b string) []Op {
+	return EditScriptLines(graphemes(a), graphemes(b))
+}
+
+// graphemes splits s into its grapheme clusters.
+func graphemes(s string) []string {
+	var clusters []string
+	start, ri := 0, 0 // ri counts the regional indicators ending the cluster
+	// pict is whether the cluster ends in an extended pictographic
+	// character and extending characters, and pictZWJ whether it ends in
+	// those and a ZWJ, after which another pictographic character joins.
+	pict, pictZWJ := false, false
+	var prev rune
+	for i, r := range s {
+		if i > 0 && !joins(prev, r, ri, pictZWJ) {
+			clusters = append(clusters, s[start:i])
+			start, ri = i, 0
+		}
+		if isRegional(r) {
+			ri++
+		} else {
+			ri = 0
+		}
+		switch {
+		case isPictographic(r):
+			pict, pictZWJ = true, false
+		case r == zwj:
+			pict, pictZWJ = false, pict
+		case unicode.In(r, unicode.Mn, unicode.Me) || isExtend(r):
+			pictZWJ = false
+		default:
+			pict, pictZWJ = false, false
+		}
+		prev = r
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+const zwj = '\u200d'
+
+// joins reports whether r continues the grapheme cluster that prev ends;
+// ri is the number of regional indicators at the end of the cluster, and
+// pictZWJ whether it ends in an extended pictographic character, extending
+// characters and a ZWJ.
+func joins(prev, r rune, ri int, pictZWJ bool) bool {
+	switch {
+	case prev == '\r' && r == '\n':
+		return true
+	case unicode.IsControl(prev) || unicode.IsControl(r):
+		return false
+	case hangulJoins(prev, r):
+		return true
+	case r == zwj || unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) || isExtend(r):
+		return true
+	case pictZWJ && isPictographic(r):
+		return true
+	case isRegional(prev) && isRegional(r):
+		return ri%2 == 1
+	}
+	return false
+}
+
+// isExtend reports whether r is one of the extending characters that are not
+// marks: variation selectors, emoji modifiers and tags.
+func isExtend(r rune) bool {
+	return r >= 0xfe00 && r <= 0xfe0f || r >= 0x1f3fb && r <= 0x1f3ff ||
+		r >= 0xe0020 && r <= 0xe007f || r >= 0xe0100 && r <= 0xe01ef
+}
+
+func isPictographic(r rune) bool {
+	return r == 0xa9 || r == 0xae || r == 0x203c || r == 0x2049 ||
+		r >= 0x2300 && r <= 0x23ff || r >= 0x2600 && r <= 0x27bf ||
+		r >= 0x2b00 && r <= 0x2bff || r >= 0x1f000 && r <= 0x1faff && !isRegional(r) && !isExtend(r)
+}
+
+func isRegional(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// hangulJoins reports whether the Hangul jamo or syllable r continues the
+// syllable that prev ends.
+func hangulJoins(prev, r rune) bool {
+	switch p, c := hangulType(prev), hangulType(r); p {
+	case 'L':
+		return c == 'L' || c == 'V' || c == 'v' || c == 't'
+	case 'V', 'v':
+		return c == 'V' || c == 'T'
+	case 'T', 't':
+		return c == 'T'
+	}
+	return false
+}
+
+// hangulType returns the Hangul syllable type of r: 'L', 'V' or 'T' for a
+// leading, vowel or trailing jamo, 'v' for a precomposed syllable of a
+// leading jamo and a vowel and 't' for one that also has a trailing jamo,
+// and 0 for other runes.
+func hangulType(r rune) byte {
+	switch {
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return 'L'
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return 'V'
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return 'T'
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return 'v'
+		}
+		return 't'
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	var tests = []struct {
+		s        string
+		clusters []string
+	}{
+		{"", nil},
+		{"abc", []string{"a", "b", "c"}},
+		{"e\u0301a", []string{"e\u0301", "a"}},
+		{"a\r\nb\n\n", []string{"a", "\r\n", "b", "\n", "\n"}},
+		// man, ZWJ, woman, ZWJ, girl
+		{"x\U0001F468\u200d\U0001F469\u200d\U0001F467y", []string{"x", "\U0001F468\u200d\U0001F469\u200d\U0001F467", "y"}},
+		// A ZWJ only joins pictographs after a pictograph and its
+		// extending characters.
+		{"a\u200d\U0001F600", []string{"a\u200d", "\U0001F600"}},
+		{"\U0001F600\ufe0f\u0301\u200d\U0001F600", []string{"\U0001F600\ufe0f\u0301\u200d\U0001F600"}},
+		{"\U0001F600a\u200d\U0001F600", []string{"\U0001F600", "a\u200d", "\U0001F600"}},
+		{"\U0001F600\u200d\u200d\U0001F600", []string{"\U0001F600\u200d\u200d", "\U0001F600"}},
+		{"\U0001F44D\U0001F3FD\u2764\ufe0f", []string{"\U0001F44D\U0001F3FD", "\u2764\ufe0f"}},
+		// flags of Germany and France, and a lone regional indicator
+		{"\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7\U0001F1EB", []string{"\U0001F1E9\U0001F1EA", "\U0001F1EB\U0001F1F7", "\U0001F1EB"}},
+		{"1\ufe0f\u20e3", []string{"1\ufe0f\u20e3"}},
+		{"\u1100\u1161\u11a8\uac00\u11a8\uac01", []string{"\u1100\u1161\u11a8", "\uac00\u11a8", "\uac01"}},
+		{"a\xff\u0301", []string{"a", "\xff\u0301"}},
+	}
+	for i, test := range tests {
+		if clusters := graphemes(test.s); !reflect.DeepEqual(clusters, test.clusters) {
+			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.clusters, clusters)
+		}
+		if s := strings.Join(graphemes(test.s), ""); s != test.s {
+			t.Errorf("test %d: clusters join to %q", i, s)
+		}
+	}
+}
+
+func TestGraphemeDiff(t *testing.T) {
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467"
+	couple := "\U0001F468\u200d\U0001F469"
+	var tests = []struct {
+		a   string
+		b   string
+		ops []Op
+	}{
+		{"", "", nil},
+		{"ab", "ab", []Op{{NoChange, 0, 0, 2, []string{"a", "b"}}}},
+		{"e\u0301", "e", []Op{{Deleted, 0, 0, 1, []string{"e\u0301"}}, {Added, 1, 0, 1, []string{"e"}}}},
+		// The couple must not match the start of the family.
+		{"a" + family, "a" + couple, []Op{{NoChange, 0, 0, 1, []string{"a"}}, {Deleted, 1, 1, 1, []string{family}}, {Added, 2, 1, 1, []string{couple}}}},
+		{family + "b", "b", []Op{{Deleted, 0, 0, 1, []string{family}}, {NoChange, 1, 0, 1, []string{"b"}}}},
+	}
+	for i, test := range tests {
+		ops := GraphemeDiff(test.a, test.b)
+		if !reflect.DeepEqual(ops, test.ops) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.ops, ops)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package diff
+
+// Histogram diff
+
+// maxOccurrences is the number of occurrences above which histogram diff
+// considers an element too common to split on.
+const maxOccurrences = 64
+
+// DiffHistogram is like Diff but uses the histogram diff strategy of git: of
+// the runs of equal elements in the two sequences, the one whose elements
+// occur least often in the left sequence is matched first, favoring longer
+// runs among equally rare ones, and the parts before and after it are diffed
+// recursively. Where all common elements occur more than 64 times, it falls
+// back to Diff. Like patience diff, this aligns on distinctive lines rather
+// than on braces and blank lines, but it also works where no line is unique.
+// The result is a common subsequence that need not be the longest. data.Common
+// is called under the same contract as for Diff, and the returned length of
+// the edit script is that of the reported subsequence.
+//
+// As with DiffPatience, counting occurrences calls Equal for every pair of
+// elements of the part being diffed.
+func DiffHistogram(data Interface) int {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	h := &histogram{data: data, runs: runs{data: data}}
+	h.compare(0, n, 0, m)
+	h.runs.flush(n, m)
+	return n + m - 2*h.runs.total
+}
+
+type histogram struct {
+	data Interface
+	runs runs
+}
+
+func (h *histogram) compare(i0, i1, j0, j1 int) {
+	if i0 == i1 || j0 == j1 {
+		return
+	}
+	// count[j] is the number of occurrences of right[j] in left[i0:i1].
+	count := make([]int, j1-j0)
+	found := false
+	for i := i0; i < i1; i++ {
+		for j := j0; j < j1; j++ {
+			if h.data.Equal(i, j) {
+				count[j-j0]++
+				found = true
+			}
+		}
+	}
+	if !found {
+		return
+	}
+
+	// Find the run of equal elements with the fewest occurrences.
+	bestI, bestJ, bestN, bestCount := 0, 0, 0, maxOccurrences+1
+	for j := j0; j < j1; j++ {
+		if c := count[j-j0]; c == 0 || c > bestCount {
+			continue
+		}
+		for i := i0; i < i1; i++ {
+			if !h.data.Equal(i, j) {
+				continue
+			}
+			s, e := 0, 1 // the run is left[i-s:i+e], right[j-s:j+e]
+			c := count[j-j0]
+			for i-s > i0 && j-s > j0 && h.data.Equal(i-s-1, j-s-1) {
+				s++
+				c = min(c, count[j-s-j0])
+			}
+			for i+e < i1 && j+e < j1 && h.data.Equal(i+e, j+e) {
+				c = min(c, count[j+e-j0])
+				e++
+			}
+			if c < bestCount || (c == bestCount && s+e > bestN) {
+				bestI, bestJ, bestN, bestCount = i-s, j-s, s+e, c
+			}
+		}
+	}
+	if bestN == 0 {
+		Diff(&window{h.data, &h.runs, i0, i1, j0, j1})
+		return
+	}
+	h.compare(i0, bestI, j0, bestJ)
+	h.runs.add(bestI, bestJ, bestN)
+	h.compare(bestI+bestN, i1, bestJ+bestN, j1)
+}
@@ -0,0 +1,49 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffHistogramRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 20, "abcdefgh"), "")
+		b := strings.Split(randString(r, 20, "abcdefgh"), "")
+		d := &lineDiff{a: a, b: b}
+		checkCommon(t, d, DiffHistogram(d))
+	}
+}
+
+func TestDiffHistogramReorder(t *testing.T) {
+	a, b := source("x", "z", "v", "y", "w"), source("w", "y", "v", "z", "x")
+
+	myers := &lineDiff{a: a, b: b}
+	Diff(myers)
+	histogram := &lineDiff{a: a, b: b}
+	checkCommon(t, histogram, DiffHistogram(histogram))
+
+	// Like patience diff, histogram diff keeps the function y together
+	// instead of matching braces and return statements across functions.
+	if m, h := changeGroups(myers), changeGroups(histogram); m != 6 || h != 3 {
+		t.Errorf("want 6 change groups for Myers and 3 for histogram diff, have %d and %d", m, h)
+	}
+	if want := [][3]int{{15, 2, 9}, {26, 13, 2}, {28, 28, 0}}; !reflect.DeepEqual(histogram.common, want) {
+		t.Errorf("want %v\nhave %v", want, histogram.common)
+	}
+}
+
+func TestDiffHistogramCommon(t *testing.T) {
+	// Every element occurs more than once, so patience diff finds nothing
+	// to align on, while histogram diff picks the longest run containing
+	// the rarer "b".
+	a := strings.Split("aabaab", "")
+	b := strings.Split("bbaa", "")
+	d := &lineDiff{a: a, b: b}
+	checkCommon(t, d, DiffHistogram(d))
+	if want := [][3]int{{2, 1, 3}, {6, 4, 0}}; !reflect.DeepEqual(d.common, want) {
+		t.Errorf("want %v\nhave %v", want, d.common)
+	}
+}
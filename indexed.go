@@ -0,0 +1,49 @@
+package diff
+
+// Indexed sequences
+
+// SideBySideIndexed is like SideBySide but for lines that are not held in
+// slices, such as those of a memory-mapped file: the left sequence has n and
+// the right one m lines, at(0, i) returns line i of the left and at(1, j)
+// line j of the right sequence, and eq(i, j) reports whether they are equal.
+// at is only called for the lines of the result, after the diff has been
+// computed with eq.
+func SideBySideIndexed(n, m int, at func(side, idx int) string, eq func(i, j int) bool) []SideBySideLine {
+	d := &indexedSideBySide{n: n, m: m, at: at, eq: eq}
+	Diff(d)
+	return d.lines
+}
+
+type indexedSideBySide struct {
+	n, m  int
+	at    func(side, idx int) string
+	eq    func(i, j int) bool
+	i, j  int
+	lines []SideBySideLine
+}
+
+func (d *indexedSideBySide) Lengths() (int, int) { return d.n, d.m }
+func (d *indexedSideBySide) Equal(i, j int) bool { return d.eq(i, j) }
+func (d *indexedSideBySide) Common(i, j, n int) {
+	for d.i < i || d.j < j {
+		line := SideBySideLine{Type: Changed}
+		if d.i < i {
+			line.Left = d.at(0, d.i)
+			d.i++
+		} else {
+			line.Type = Added
+		}
+		if d.j < j {
+			line.Right = d.at(1, d.j)
+			d.j++
+		} else {
+			line.Type = Deleted
+		}
+		d.lines = append(d.lines, line)
+	}
+	for ; n > 0; n-- {
+		d.lines = append(d.lines, SideBySideLine{Left: d.at(0, d.i), Right: d.at(1, d.j), Type: NoChange})
+		d.i++
+		d.j++
+	}
+}
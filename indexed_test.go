@@ -0,0 +1,40 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSideBySideIndexed(t *testing.T) {
+	// The lines are read from the text of the files on demand.
+	a := "a\nb\nc\nd\n"
+	b := "a\nx\nc\nd\ne\n"
+	line := func(s string, k int) string { return strings.Split(s, "\n")[k] }
+	at := func(side, idx int) string {
+		if side == 0 {
+			return line(a, idx)
+		}
+		return line(b, idx)
+	}
+	eq := func(i, j int) bool { return line(a, i) == line(b, j) }
+	lines := SideBySideIndexed(4, 5, at, eq)
+	want := SideBySide(strings.Split(a, "\n")[:4], strings.Split(b, "\n")[:5])
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+
+	for i, test := range diffTests {
+		at := func(side, idx int) string {
+			if side == 0 {
+				return test.a[idx : idx+1]
+			}
+			return test.b[idx : idx+1]
+		}
+		eq := func(i, j int) bool { return test.a[i] == test.b[j] }
+		lines := SideBySideIndexed(len(test.a), len(test.b), at, eq)
+		if want := SideBySide(strings.Split(test.a, ""), strings.Split(test.b, "")); !reflect.DeepEqual(lines, want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, want, lines)
+		}
+	}
+}
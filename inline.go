@@ -0,0 +1,24 @@
+package diff
+
+// Inline diff
+
+// An InlineLine is a line of an inline diff, which shows the lines of both
+// sequences in a single column.
+type InlineLine struct {
+	Text string
+	Type int // NoChange, Added or Deleted
+}
+
+// Inline returns the inline diff from a to b: the lines of the EditScript
+// from a to b in order, each tagged with the Kind of its op. Within each run
+// of changes, the deleted lines come before the added ones, as in a unified
+// diff.
+func Inline(a, b []string) []InlineLine {
+	var lines []InlineLine
+	for _, op := range EditScriptLines(a, b) {
+		for _, l := range op.Lines {
+			lines = append(lines, InlineLine{l, op.Kind})
+		}
+	}
+	return lines
+}
@@ -0,0 +1,30 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	var tests = []struct {
+		a, b []string
+		want []InlineLine
+	}{
+		{nil, nil, nil},
+		{[]string{"a"}, []string{"a"}, []InlineLine{{"a", NoChange}}},
+		{[]string{"a", "b", "c"}, []string{"a", "x", "y", "c", "d"}, []InlineLine{
+			{"a", NoChange},
+			{"b", Deleted},
+			{"x", Added},
+			{"y", Added},
+			{"c", NoChange},
+			{"d", Added},
+		}},
+		{[]string{"a", "b"}, nil, []InlineLine{{"a", Deleted}, {"b", Deleted}}},
+	}
+	for i, test := range tests {
+		if have := Inline(test.a, test.b); !reflect.DeepEqual(have, test.want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.want, have)
+		}
+	}
+}
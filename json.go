@@ -0,0 +1,152 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSON
+
+// jsonName returns the name of the line type t in JSON, which is that of its
+// constant in lower case, such as "nochange".
+func jsonName(t ChangeType) string {
+	return strings.ToLower(changeTypeNames[t])
+}
+
+// MarshalJSON encodes l as a JSON object with its Type given by name, for
+// example {"left":"a","right":"b","type":"changed"}.
+func (l SideBySideLine) MarshalJSON() ([]byte, error) {
+	if l.Type < 0 || int(l.Type) >= len(changeTypeNames) {
+		return nil, fmt.Errorf("diff: invalid line type %d", l.Type)
+	}
+	type line SideBySideLine
+	return json.Marshal(struct {
+		line
+		Type string `json:"type"`
+	}{line(l), jsonName(l.Type)})
+}
+
+// UnmarshalJSON decodes a line encoded by MarshalJSON.
+func (l *SideBySideLine) UnmarshalJSON(data []byte) error {
+	type line SideBySideLine
+	v := struct {
+		*line
+		Type string `json:"type"`
+	}{line: (*line)(l)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	for t := range changeTypeNames {
+		if jsonName(ChangeType(t)) == v.Type {
+			l.Type = ChangeType(t)
+			return nil
+		}
+	}
+	return fmt.Errorf("diff: invalid line type %q", v.Type)
+}
+
+// A Result holds a side-by-side diff together with its edit distance.
+type Result struct {
+	Lines []SideBySideLine `json:"lines"`
+	Edits int              `json:"edits"`
+}
+
+// SideBySideResult computes the side-by-side diff of two sets of lines and
+// the length of the edit script from a to b.
+func SideBySideResult(a, b []string) Result {
+	d := &sideBySide{a: a, b: b, ha: HashLines(a), hb: HashLines(b)}
+	edits := Diff(d)
+	return Result{d.lines, edits}
+}
+
+// JSONDiff compares the JSON documents a and b by their values rather than
+// their text. Both are formatted canonically, with the keys of objects sorted,
+// one array element or object member per line and an indent of two spaces,
+// and the lines are compared as by SideBySide. Numbers are kept as written,
+// so 1 and 1.0 differ. An error is returned if a or b is not a single valid
+// JSON value.
+func JSONDiff(a, b []byte) ([]SideBySideLine, error) {
+	la, err := canonicalJSON(a)
+	if err != nil {
+		return nil, fmt.Errorf("diff: left JSON: %w", err)
+	}
+	lb, err := canonicalJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("diff: right JSON: %w", err)
+	}
+	return SideBySide(la, lb), nil
+}
+
+// canonicalJSON returns the lines of the canonical form of the JSON value in
+// data.
+func canonicalJSON(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("data after the JSON value")
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(out), "\n"), nil
+}
+
+// A PatchOp is an operation of a JSON Patch as defined by RFC 6902.
+type PatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONArrayPatch returns the "remove" and "add" operations of a JSON Patch
+// that turns the JSON array with the elements a into the one with the
+// elements b, following the EditScript from a to b. Elements are compared by
+// their canonical form, as JSONDiff formats them, and the values added are
+// those of b as they are. The paths are the indices at which the operations
+// apply in the array as patched by the operations before them, relative to
+// the array, such as "/3". An error is returned if an element is not valid
+// JSON.
+func JSONArrayPatch(a, b []json.RawMessage) ([]PatchOp, error) {
+	canonical := func(elems []json.RawMessage) ([]string, error) {
+		keys := make([]string, len(elems))
+		for k, e := range elems {
+			lines, err := canonicalJSON(e)
+			if err != nil {
+				return nil, fmt.Errorf("diff: element %d: %w", k, err)
+			}
+			keys[k] = strings.Join(lines, "\n")
+		}
+		return keys, nil
+	}
+	ka, err := canonical(a)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := canonical(b)
+	if err != nil {
+		return nil, err
+	}
+	var ops []PatchOp
+	for _, op := range EditScript(lineData{ka, kb}) {
+		// The elements before the op have been patched into b[:op.FromJ].
+		for k := 0; k < op.Len; k++ {
+			switch op.Kind {
+			case Deleted:
+				ops = append(ops, PatchOp{Op: "remove", Path: fmt.Sprintf("/%d", op.FromJ)})
+			case Added:
+				j := op.FromJ + k
+				ops = append(ops, PatchOp{Op: "add", Path: fmt.Sprintf("/%d", j), Value: b[j]})
+			}
+		}
+	}
+	return ops, nil
+}
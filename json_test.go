@@ -0,0 +1,184 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResultJSON(t *testing.T) {
+	r := SideBySideResult([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"lines":[` +
+		`{"left":"a","right":"a","type":"nochange"},` +
+		`{"left":"b","right":"x","type":"changed"},` +
+		`{"left":"c","right":"c","type":"nochange"},` +
+		`{"left":"","right":"d","type":"added"}],"edits":3}`
+	if string(data) != want {
+		t.Errorf("want %s\nhave %s", want, data)
+	}
+	var have Result
+	if err := json.Unmarshal(data, &have); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, r) {
+		t.Errorf("want %v\nhave %v", r, have)
+	}
+}
+
+func TestSideBySideLineJSON(t *testing.T) {
+	for _, l := range []SideBySideLine{
+		{Type: Skipped, Count: 42},
+		{Left: "x", Type: Moved, MoveID: 1},
+		{Left: "ab", Right: "b", Type: Changed, LeftSpans: []Span{{0, 1, true}, {1, 2, false}}, RightSpans: []Span{{0, 1, false}}},
+	} {
+		data, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var have SideBySideLine
+		if err := json.Unmarshal(data, &have); err != nil || !reflect.DeepEqual(have, l) {
+			t.Errorf("%s: want %v, have %v, %v", data, l, have, err)
+		}
+	}
+	// Every type has a name in JSON, which must not change.
+	for typ, name := range []string{"nochange", "added", "deleted", "changed", "skipped", "moved", "nearmatch"} {
+		data, err := json.Marshal(SideBySideLine{Type: ChangeType(typ)})
+		if want := fmt.Sprintf(`{"left":"","right":"","type":%q}`, name); err != nil || string(data) != want {
+			t.Errorf("type %d: want %s, have %s, %v", typ, want, data, err)
+		}
+	}
+	if _, err := json.Marshal(SideBySideLine{Type: 99}); err == nil {
+		t.Error("want error for invalid type")
+	}
+	var l SideBySideLine
+	if err := json.Unmarshal([]byte(`{"type":"bogus"}`), &l); err == nil || err.Error() != `diff: invalid line type "bogus"` {
+		t.Errorf("want error for invalid type, have %v", err)
+	}
+}
+
+func TestJSONDiff(t *testing.T) {
+	a := `{"b": [1, 2], "a": {"y": true, "x": null}}`
+	lines, err := JSONDiff([]byte(a), []byte(`{"a":{"x":null,"y":true},"b":[1,2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range lines {
+		if l.Type != NoChange {
+			t.Errorf("reordered keys: have %v", lines)
+			break
+		}
+	}
+
+	lines, err = JSONDiff([]byte(a), []byte(`{"b": [1, 3], "a": {"y": true, "x": null}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SideBySideLine{
+		{Left: "{", Right: "{", Type: NoChange},
+		{Left: `  "a": {`, Right: `  "a": {`, Type: NoChange},
+		{Left: `    "x": null,`, Right: `    "x": null,`, Type: NoChange},
+		{Left: `    "y": true`, Right: `    "y": true`, Type: NoChange},
+		{Left: "  },", Right: "  },", Type: NoChange},
+		{Left: `  "b": [`, Right: `  "b": [`, Type: NoChange},
+		{Left: "    1,", Right: "    1,", Type: NoChange},
+		{Left: "    2", Right: "    3", Type: Changed},
+		{Left: "  ]", Right: "  ]", Type: NoChange},
+		{Left: "}", Right: "}", Type: NoChange},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+
+	for _, s := range []string{"", "{", `{"a": 1} 2`} {
+		if _, err := JSONDiff([]byte(s), []byte("1")); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
+
+func TestNearMatchJSON(t *testing.T) {
+	l := SideBySideLine{Left: "B", Right: "b", Type: NearMatch}
+	data, err := json.Marshal(l)
+	if err != nil || string(data) != `{"left":"B","right":"b","type":"nearmatch"}` {
+		t.Errorf("have %s, %v", data, err)
+	}
+	var have SideBySideLine
+	if err := json.Unmarshal(data, &have); err != nil || !reflect.DeepEqual(have, l) {
+		t.Errorf("want %v\nhave %v, %v", l, have, err)
+	}
+}
+
+// applyArrayPatch applies the array operations ops to a.
+func applyArrayPatch(t *testing.T, a []json.RawMessage, ops []PatchOp) []json.RawMessage {
+	t.Helper()
+	p := append([]json.RawMessage(nil), a...)
+	for _, op := range ops {
+		var k int
+		if _, err := fmt.Sscanf(op.Path, "/%d", &k); err != nil {
+			t.Fatalf("%v: %v", op, err)
+		}
+		switch {
+		case op.Op == "remove" && k < len(p):
+			p = append(p[:k], p[k+1:]...)
+		case op.Op == "add" && k <= len(p):
+			p = append(p[:k], append([]json.RawMessage{op.Value}, p[k:]...)...)
+		default:
+			t.Fatalf("cannot apply %v to %d elements", op, len(p))
+		}
+	}
+	return p
+}
+
+func TestJSONArrayPatch(t *testing.T) {
+	raw := func(s ...string) []json.RawMessage {
+		elems := make([]json.RawMessage, len(s))
+		for k := range s {
+			elems[k] = json.RawMessage(s[k])
+		}
+		return elems
+	}
+	a := raw("1", `"x"`, `{"a": 1, "b": 2}`, "true", "null")
+	b := raw("1", `{"b":2,"a":1}`, "false", "3", "null", "[4]")
+	ops, err := JSONArrayPatch(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PatchOp{
+		{Op: "remove", Path: "/1"},
+		{Op: "remove", Path: "/2"},
+		{Op: "add", Path: "/2", Value: b[2]},
+		{Op: "add", Path: "/3", Value: b[3]},
+		{Op: "add", Path: "/5", Value: b[5]},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("want %v\nhave %v", want, ops)
+	}
+	data, err := json.Marshal(ops[:3])
+	if err != nil || string(data) != `[{"op":"remove","path":"/1"},{"op":"remove","path":"/2"},{"op":"add","path":"/2","value":false}]` {
+		t.Errorf("JSON: have %s, %v", data, err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := raw(strings.Split(randString(r, 12, "1234"), "")...)
+		b := raw(strings.Split(randString(r, 12, "1234"), "")...)
+		ops, err := JSONArrayPatch(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := applyArrayPatch(t, a, ops); fmt.Sprintf("%s", p) != fmt.Sprintf("%s", b) {
+			t.Fatalf("%s to %s: %v gives %s", a, b, ops, p)
+		}
+	}
+
+	if _, err := JSONArrayPatch(raw("1", "{"), nil); err == nil {
+		t.Error("invalid element: want error")
+	}
+}
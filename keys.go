@@ -0,0 +1,55 @@
+package diff
+
+import "errors"
+
+// Key sets
+
+// KeyDiff compares two sorted lists of distinct keys, such as the keys of two
+// maps, and returns the keys only in bKeys, those only in aKeys and those in
+// both, each in sorted order. For lists that are not sorted, the result is
+// that of the diff between them rather than of the sets.
+func KeyDiff(aKeys, bKeys []string) (added, removed, common []string) {
+	for c := range Changes(lineData{aKeys, bKeys}) {
+		switch c.Kind {
+		case Added:
+			added = append(added, bKeys[c.J:c.J+c.N]...)
+		case Deleted:
+			removed = append(removed, aKeys[c.I:c.I+c.N]...)
+		default:
+			common = append(common, aKeys[c.I:c.I+c.N]...)
+		}
+	}
+	return added, removed, common
+}
+
+// ErrNotSorted is the value SortedDiff panics with if its input is not
+// sorted.
+var ErrNotSorted = errors.New("diff: lines not sorted")
+
+// SortedDiff compares two sorted lists of lines as multisets, merging them in
+// O(n+m) time rather than computing a longest common subsequence. Lines in
+// both lists are NoChange, as many times as they occur in both, and the
+// others are Deleted from a or Added from b, all in sorted order. SortedDiff
+// panics with ErrNotSorted if a or b is not sorted.
+func SortedDiff(a, b []string) []SideBySideLine {
+	var lines []SideBySideLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if (i > 0 && i < len(a) && a[i] < a[i-1]) || (j > 0 && j < len(b) && b[j] < b[j-1]) {
+			panic(ErrNotSorted)
+		}
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			lines = append(lines, SideBySideLine{Left: a[i], Type: Deleted})
+			i++
+		case i == len(a) || b[j] < a[i]:
+			lines = append(lines, SideBySideLine{Right: b[j], Type: Added})
+			j++
+		default:
+			lines = append(lines, SideBySideLine{Left: a[i], Right: b[j], Type: NoChange})
+			i++
+			j++
+		}
+	}
+	return lines
+}
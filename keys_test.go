@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestKeyDiff(t *testing.T) {
+	added, removed, common := KeyDiff([]string{"a", "b", "d"}, []string{"b", "c", "d", "e"})
+	if !reflect.DeepEqual(added, []string{"c", "e"}) || !reflect.DeepEqual(removed, []string{"a"}) || !reflect.DeepEqual(common, []string{"b", "d"}) {
+		t.Errorf("have added %q, removed %q, common %q", added, removed, common)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		var a, b, wantAdded, wantRemoved, wantCommon []string
+		for k := 0; k < 10; k++ {
+			key := string(rune('a' + k))
+			inA, inB := r.Intn(2) == 0, r.Intn(2) == 0
+			if inA {
+				a = append(a, key)
+			}
+			if inB {
+				b = append(b, key)
+			}
+			switch {
+			case inA && inB:
+				wantCommon = append(wantCommon, key)
+			case inA:
+				wantRemoved = append(wantRemoved, key)
+			case inB:
+				wantAdded = append(wantAdded, key)
+			}
+		}
+		added, removed, common := KeyDiff(a, b)
+		if !slices.Equal(added, wantAdded) || !slices.Equal(removed, wantRemoved) || !slices.Equal(common, wantCommon) {
+			t.Fatalf("KeyDiff(%q, %q) = %q, %q, %q", a, b, added, removed, common)
+		}
+		if want := LCS(a, b, func(x, y string) bool { return x == y }); !slices.Equal(common, want) {
+			t.Fatalf("KeyDiff(%q, %q): common %q, LCS %q", a, b, common, want)
+		}
+	}
+}
+
+func TestSortedDiff(t *testing.T) {
+	lines := SortedDiff([]string{"a", "b", "b", "b", "d"}, []string{"b", "b", "c", "d", "d"})
+	want := []SideBySideLine{
+		{Left: "a", Type: Deleted},
+		{Left: "b", Right: "b", Type: NoChange},
+		{Left: "b", Right: "b", Type: NoChange},
+		{Left: "b", Type: Deleted},
+		{Right: "c", Type: Added},
+		{Left: "d", Right: "d", Type: NoChange},
+		{Right: "d", Type: Added},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+	if lines := SortedDiff(nil, nil); lines != nil {
+		t.Errorf("no lines: have %v", lines)
+	}
+
+	for _, test := range [][2][]string{{{"b", "a"}, nil}, {nil, {"a", "c", "b"}}} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNotSorted {
+					t.Errorf("%q: want panic with ErrNotSorted, have %v", test, r)
+				}
+			}()
+			SortedDiff(test[0], test[1])
+		}()
+	}
+}
@@ -0,0 +1,113 @@
+package diff
+
+// DiffLinear is like Diff but uses the divide-and-conquer variant of the
+// algorithm (see section 4b of the paper), which needs O(N+M) memory instead
+// of the O((N+M)²) Diff may use for very different sequences. It calls
+// data.Common under the same contract as Diff. Where several longest common
+// subsequences exist, the one reported may differ from the one Diff reports.
+func DiffLinear(data Interface) int {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	max := (n+m+1)/2 + 1
+	l := &linear{
+		data: data,
+		runs: runs{data: data},
+		vf:   make([]int, 2*max+1),
+		vb:   make([]int, 2*max+1),
+	}
+	l.compare(0, n, 0, m)
+	l.runs.flush(n, m)
+	return n + m - 2*l.runs.total
+}
+
+type linear struct {
+	data Interface
+	runs runs
+	vf   []int // furthest reaching forward paths, indexed by diagonal
+	vb   []int // furthest reaching reverse paths, indexed by diagonal
+}
+
+// compare finds the longest common subsequence of left[x0:x1] and
+// right[y0:y1].
+func (l *linear) compare(x0, x1, y0, y1 int) {
+	p := 0
+	for x0+p < x1 && y0+p < y1 && l.data.Equal(x0+p, y0+p) {
+		p++
+	}
+	l.runs.add(x0, y0, p)
+	x0 += p
+	y0 += p
+
+	s := 0
+	for x1-s > x0 && y1-s > y0 && l.data.Equal(x1-s-1, y1-s-1) {
+		s++
+	}
+	x1 -= s
+	y1 -= s
+
+	// With equal prefix and suffix removed and both sides non-empty, the
+	// edit distance is at least 2, so both halves are strictly smaller.
+	if x0 < x1 && y0 < y1 {
+		x, y, u, v := l.middleSnake(x0, x1, y0, y1)
+		l.compare(x0, x, y0, y)
+		l.runs.add(x, y, u-x)
+		l.compare(u, x1, v, y1)
+	}
+	l.runs.add(x1, y1, s)
+}
+
+// middleSnake returns the middle snake (x, y) -> (u, v) of an optimal path
+// from (x0, y0) to (x1, y1).
+func (l *linear) middleSnake(x0, x1, y0, y1 int) (x, y, u, v int) {
+	n, m := x1-x0, y1-y0
+	delta := n - m
+	odd := delta&1 != 0
+	off := len(l.vf) / 2
+	vf, vb := l.vf, l.vb
+	vf[off+1] = 0
+	vb[off+1] = 0
+	for d := 0; d <= (n+m+1)/2; d++ {
+		for k := -d; k <= d; k += 2 {
+			K := off + k
+			var x int
+			if k == -d || (k != d && vf[K-1] < vf[K+1]) {
+				x = vf[K+1]
+			} else {
+				x = vf[K-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && l.data.Equal(x0+x, y0+y) {
+				x++
+				y++
+			}
+			vf[K] = x
+			// The reverse path on the same diagonal has index delta-k.
+			if r := delta - k; odd && r >= -(d-1) && r <= d-1 && x+vb[off+r] >= n {
+				return x0 + sx, y0 + sy, x0 + x, y0 + y
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			K := off + k
+			var x int
+			if k == -d || (k != d && vb[K-1] < vb[K+1]) {
+				x = vb[K+1]
+			} else {
+				x = vb[K-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && l.data.Equal(x1-x-1, y1-y-1) {
+				x++
+				y++
+			}
+			vb[K] = x
+			if r := delta - k; !odd && r >= -d && r <= d && x+vf[off+r] >= n {
+				return x1 - x, y1 - y, x1 - sx, y1 - sy
+			}
+		}
+	}
+	panic("diff: no middle snake found")
+}
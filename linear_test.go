@@ -0,0 +1,60 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLinear(t *testing.T) {
+	testDiff(t, DiffLinear)
+}
+
+func TestDiffLinearDisjoint(t *testing.T) {
+	for _, test := range []struct{ a, b string }{
+		{"abc", "xyz"},
+		{"", "xyz"},
+		{"abc", ""},
+		{"a", "xyzw"},
+	} {
+		d := &stringDiff{a: test.a, b: test.b}
+		edits := DiffLinear(d)
+		if want := len(test.a) + len(test.b); edits != want {
+			t.Errorf("%q, %q: want %d edits, have %d", test.a, test.b, want, edits)
+		}
+		if want := []string{""}; !reflect.DeepEqual(d.lcsa, want) {
+			t.Errorf("%q, %q: want lcs %q, have %q", test.a, test.b, want, d.lcsa)
+		}
+	}
+}
+
+func TestDiffLinearRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a, b := randString(r, 20, "abc"), randString(r, 20, "abc")
+		want := Diff(&stringDiff{a: a, b: b})
+		d := &stringDiff{a: a, b: b}
+		if have := DiffLinear(d); have != want {
+			t.Fatalf("%q, %q: want %d edits, have %d", a, b, want, have)
+		}
+		lcs := strings.Join(d.lcsa, "")
+		if len(a)+len(b)-2*len(lcs) != want {
+			t.Fatalf("%q, %q: lcs %q is not the longest", a, b, d.lcsa)
+		}
+		if !reflect.DeepEqual(d.lcsa, d.lcsb) {
+			t.Fatalf("%q, %q: lcsa %q != lcsb %q", a, b, d.lcsa, d.lcsb)
+		}
+		if last := d.lcsa[len(d.lcsa)-1]; !strings.HasSuffix(a, last) || !strings.HasSuffix(b, last) {
+			t.Fatalf("%q, %q: last common part %q is not trailing", a, b, last)
+		}
+	}
+}
+
+func randString(r *rand.Rand, max int, alphabet string) string {
+	b := make([]byte, r.Intn(max+1))
+	for i := range b {
+		b[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,175 @@
+package diff
+
+import "sort"
+
+// Three-way merge
+
+// Merge3 merges the changes from base to left and from base to right. Changes
+// to separate parts of base are both applied. Where the changes overlap and
+// differ, the merged lines contain a conflict:
+//
+//	<<<<<<<
+//	left's version
+//	=======
+//	right's version
+//	>>>>>>>
+//
+// Merge3 reports whether there were any conflicts. Identical changes on both
+// sides do not conflict.
+func Merge3(base, left, right []string) ([]string, bool) {
+	cs := append(changes(base, left, 0), changes(base, right, 1)...)
+	sort.SliceStable(cs, func(x, y int) bool { return cs[x].start < cs[y].start })
+
+	var merged []string
+	conflict := false
+	pos := 0
+	for k := 0; k < len(cs); {
+		// Collect the cluster of changes overlapping cs[k].
+		start, end := cs[k].start, cs[k].end
+		var sides [2][]change
+		for ; k < len(cs) && (cs[k].start < end || cs[k].start == start); k++ {
+			end = max(end, cs[k].end)
+			sides[cs[k].side] = append(sides[cs[k].side], cs[k])
+		}
+		merged = append(merged, base[pos:start]...)
+		l := applyChanges(base, start, end, sides[0])
+		r := applyChanges(base, start, end, sides[1])
+		switch {
+		case len(sides[1]) == 0 || equalLines(l, r):
+			merged = append(merged, l...)
+		case len(sides[0]) == 0:
+			merged = append(merged, r...)
+		default:
+			conflict = true
+			merged = append(merged, "<<<<<<<")
+			merged = append(merged, l...)
+			merged = append(merged, "=======")
+			merged = append(merged, r...)
+			merged = append(merged, ">>>>>>>")
+		}
+		pos = end
+	}
+	return append(merged, base[pos:]...), conflict
+}
+
+// A change replaces base[start:end] with lines.
+type change struct {
+	start int
+	end   int
+	lines []string
+	side  int
+}
+
+// changes returns the changes from base to b, in order.
+func changes(base, b []string, side int) []change {
+	var cs []change
+	for _, op := range EditScript(lineData{base, b}) {
+		switch op.Kind {
+		case Deleted:
+			cs = append(cs, change{op.FromI, op.FromI + op.Len, nil, side})
+		case Added:
+			lines := b[op.FromJ : op.FromJ+op.Len]
+			if n := len(cs) - 1; n >= 0 && cs[n].end == op.FromI && cs[n].lines == nil {
+				cs[n].lines = lines
+			} else {
+				cs = append(cs, change{op.FromI, op.FromI, lines, side})
+			}
+		}
+	}
+	return cs
+}
+
+// applyChanges returns base[start:end] with the changes applied.
+func applyChanges(base []string, start, end int, cs []change) []string {
+	var lines []string
+	for _, c := range cs {
+		lines = append(lines, base[start:c.start]...)
+		lines = append(lines, c.lines...)
+		start = c.end
+	}
+	return append(lines, base[start:end]...)
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// A ThreeWayLine is a row of the alignment of base, left and right that
+// Align3 returns. A row either holds a line of base, with BaseType NoChange
+// if both sides keep it and Changed if either side changes or deletes it, or
+// only lines added on either side, with BaseType Added and an empty Base.
+// LeftType and RightType tell what became of the row on each side: NoChange
+// for the line of base kept or, on a row without one, for nothing added,
+// Changed for the line that replaces the line of base, Deleted for the line
+// of base deleted, and Added for a line added. Left and Right are empty where
+// the side has no line.
+type ThreeWayLine struct {
+	Base, Left, Right             string
+	BaseType, LeftType, RightType int
+}
+
+// Align3 aligns base, left and right for a three-pane merge view, diffing
+// base with left and with right as SideBySide does and interleaving the two
+// diffs along base. The lines the two sides add before the same line of base
+// are themselves aligned by SideBySide, so that a line added on both sides
+// shares a row.
+func Align3(base, left, right []string) []ThreeWayLine {
+	l, r := alignSide(base, left), alignSide(base, right)
+	var lines []ThreeWayLine
+	for p := 0; p <= len(base); p++ {
+		for _, s := range SideBySide(l.added[p], r.added[p]) {
+			line := ThreeWayLine{Left: s.Left, Right: s.Right, BaseType: Added, LeftType: Added, RightType: Added}
+			switch s.Type {
+			case Deleted:
+				line.RightType = NoChange
+			case Added:
+				line.LeftType = NoChange
+			}
+			lines = append(lines, line)
+		}
+		if p == len(base) {
+			break
+		}
+		lt, rt := l.kept[p].Type, r.kept[p].Type
+		line := ThreeWayLine{Base: base[p], BaseType: Changed, LeftType: int(lt), RightType: int(rt)}
+		if lt == NoChange && rt == NoChange {
+			line.BaseType = NoChange
+		}
+		if lt != Deleted {
+			line.Left = l.kept[p].Right
+		}
+		if rt != Deleted {
+			line.Right = r.kept[p].Right
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// An alignedSide is the side-by-side diff from base to one side, split into
+// the line for each line of base and the lines added before each line of
+// base and at the end.
+type alignedSide struct {
+	kept  []SideBySideLine
+	added [][]string
+}
+
+func alignSide(base, b []string) alignedSide {
+	s := alignedSide{added: make([][]string, len(base)+1)}
+	for _, l := range SideBySide(base, b) {
+		if l.Type == Added {
+			s.added[len(s.kept)] = append(s.added[len(s.kept)], l.Right)
+		} else {
+			s.kept = append(s.kept, l)
+		}
+	}
+	return s
+}
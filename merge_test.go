@@ -0,0 +1,175 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	var tests = []struct {
+		base, left, right []string
+		merged            []string
+		conflict          bool
+	}{{
+		[]string{"a", "b", "c"},
+		[]string{"a", "b", "c"},
+		[]string{"a", "b", "c"},
+		[]string{"a", "b", "c"},
+		false,
+	}, {
+		// Separate changes on both sides.
+		[]string{"a", "b", "c", "d", "e"},
+		[]string{"A", "b", "c", "d", "e"},
+		[]string{"a", "b", "c", "d", "E", "f"},
+		[]string{"A", "b", "c", "d", "E", "f"},
+		false,
+	}, {
+		// The same change on both sides.
+		[]string{"a", "b", "c"},
+		[]string{"a", "x", "c"},
+		[]string{"a", "x", "c"},
+		[]string{"a", "x", "c"},
+		false,
+	}, {
+		// Only one side changed.
+		[]string{"a", "b", "c"},
+		[]string{"a", "b", "c"},
+		[]string{"a", "c"},
+		[]string{"a", "c"},
+		false,
+	}, {
+		// Conflicting changes.
+		[]string{"a", "b", "c"},
+		[]string{"a", "x", "c"},
+		[]string{"a", "y", "c"},
+		[]string{"a", "<<<<<<<", "x", "=======", "y", ">>>>>>>", "c"},
+		true,
+	}, {
+		// Conflicting insertions at the same place.
+		[]string{"a", "b"},
+		[]string{"a", "x", "b"},
+		[]string{"a", "y", "b"},
+		[]string{"a", "<<<<<<<", "x", "=======", "y", ">>>>>>>", "b"},
+		true,
+	}, {
+		// An insertion within a region deleted by the other side.
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "b", "x", "c", "d"},
+		[]string{"a", "d"},
+		[]string{"a", "<<<<<<<", "b", "x", "c", "=======", ">>>>>>>", "d"},
+		true,
+	}, {
+		// Overlapping changes, partly identical.
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "x", "y", "d"},
+		[]string{"a", "x", "c", "d"},
+		[]string{"a", "<<<<<<<", "x", "y", "=======", "x", "c", ">>>>>>>", "d"},
+		true,
+	}, {
+		// Adjacent changes on either side.
+		[]string{"a", "b", "c"},
+		[]string{"a", "x", "c"},
+		[]string{"a", "b", "y"},
+		[]string{"a", "x", "y"},
+		false,
+	}}
+	for i, test := range tests {
+		merged, conflict := Merge3(test.base, test.left, test.right)
+		if !reflect.DeepEqual(merged, test.merged) || conflict != test.conflict {
+			t.Errorf("test %d:\nwant %q %v\nhave %q %v\n", i, test.merged, test.conflict, merged, conflict)
+		}
+	}
+}
+
+func TestAlign3(t *testing.T) {
+	var tests = []struct {
+		base, left, right []string
+		lines             []ThreeWayLine
+	}{{
+		[]string{"a", "b"},
+		[]string{"a", "b"},
+		[]string{"a", "b"},
+		[]ThreeWayLine{
+			{"a", "a", "a", NoChange, NoChange, NoChange},
+			{"b", "b", "b", NoChange, NoChange, NoChange},
+		},
+	}, {
+		// A change on one side and a deletion on the other.
+		[]string{"a", "b", "c"},
+		[]string{"a", "x", "c"},
+		[]string{"a", "b"},
+		[]ThreeWayLine{
+			{"a", "a", "a", NoChange, NoChange, NoChange},
+			{"b", "x", "b", Changed, Changed, NoChange},
+			{"c", "c", "", Changed, NoChange, Deleted},
+		},
+	}, {
+		// Insertions from both sides at the same place, one of them the
+		// same on both.
+		[]string{"a", "b"},
+		[]string{"a", "x", "y", "b"},
+		[]string{"a", "y", "z", "w", "b"},
+		[]ThreeWayLine{
+			{"a", "a", "a", NoChange, NoChange, NoChange},
+			{"", "x", "", Added, Added, NoChange},
+			{"", "y", "y", Added, Added, Added},
+			{"", "", "z", Added, NoChange, Added},
+			{"", "", "w", Added, NoChange, Added},
+			{"b", "b", "b", NoChange, NoChange, NoChange},
+		},
+	}, {
+		// Different insertions at the start and additions at the end.
+		[]string{"a"},
+		[]string{"x", "a", "e"},
+		[]string{"y", "a"},
+		[]ThreeWayLine{
+			{"", "x", "y", Added, Added, Added},
+			{"a", "a", "a", NoChange, NoChange, NoChange},
+			{"", "e", "", Added, Added, NoChange},
+		},
+	}, {
+		// A change followed by an insertion on one side, with the other
+		// side inserting before the next line of base.
+		[]string{"a", "b"},
+		[]string{"x", "y", "b"},
+		[]string{"a", "z", "b"},
+		[]ThreeWayLine{
+			{"a", "x", "a", Changed, Changed, NoChange},
+			{"", "y", "z", Added, Added, Added},
+			{"b", "b", "b", NoChange, NoChange, NoChange},
+		},
+	}, {
+		nil, nil, []string{"a"},
+		[]ThreeWayLine{{"", "", "a", Added, NoChange, Added}},
+	}}
+	for i, test := range tests {
+		if lines := Align3(test.base, test.left, test.right); !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.lines, lines)
+		}
+	}
+
+	// The columns hold base, left and right.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		base := strings.Split(randString(r, 10, "abc"), "")
+		left := strings.Split(randString(r, 10, "abc"), "")
+		right := strings.Split(randString(r, 10, "abc"), "")
+		var b, l, rt []string
+		for _, line := range Align3(base, left, right) {
+			if line.BaseType != Added {
+				b = append(b, line.Base)
+			}
+			if line.LeftType != Deleted && (line.LeftType != NoChange || line.BaseType != Added) {
+				l = append(l, line.Left)
+			}
+			if line.RightType != Deleted && (line.RightType != NoChange || line.BaseType != Added) {
+				rt = append(rt, line.Right)
+			}
+		}
+		if !equalLines(b, base) || !equalLines(l, left) || !equalLines(rt, right) {
+			t.Errorf("%q, %q, %q: columns hold %q, %q, %q", base, left, right, b, l, rt)
+		}
+	}
+}
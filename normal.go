@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Normal diff
+
+// Normal returns the diff from a to b in the normal output format of the diff
+// command, as a sequence of "a", "c" and "d" commands. It returns the empty
+// string if a and b are equal.
+func Normal(a, b []string) string {
+	var w strings.Builder
+	ops := EditScriptLines(a, b)
+	for k := 0; k < len(ops); k++ {
+		var del, add Op
+		switch op := ops[k]; op.Kind {
+		case NoChange:
+			continue
+		case Deleted:
+			del = op
+			if k+1 < len(ops) && ops[k+1].Kind == Added {
+				k++
+				add = ops[k]
+			}
+		case Added:
+			add = op
+		}
+		switch {
+		case add.Len == 0:
+			fmt.Fprintf(&w, "%sd%d\n", normalRange(del.FromI, del.Len), del.FromJ)
+		case del.Len == 0:
+			fmt.Fprintf(&w, "%da%s\n", add.FromI, normalRange(add.FromJ, add.Len))
+		default:
+			fmt.Fprintf(&w, "%sc%s\n", normalRange(del.FromI, del.Len), normalRange(add.FromJ, add.Len))
+		}
+		for _, l := range del.Lines {
+			fmt.Fprintf(&w, "< %s\n", l)
+		}
+		if del.Len > 0 && add.Len > 0 {
+			w.WriteString("---\n")
+		}
+		for _, l := range add.Lines {
+			fmt.Fprintf(&w, "> %s\n", l)
+		}
+	}
+	return w.String()
+}
+
+// normalRange formats the 1-based range of n lines from index i.
+func normalRange(i, n int) string {
+	if n == 1 {
+		return fmt.Sprint(i + 1)
+	}
+	return fmt.Sprintf("%d,%d", i+1, i+n)
+}
@@ -0,0 +1,31 @@
+package diff
+
+import "testing"
+
+func TestNormal(t *testing.T) {
+	var tests = []struct {
+		a    []string
+		b    []string
+		want string
+	}{
+		{[]string{"a"}, []string{"a"}, ""},
+		{nil, []string{"a", "b"}, "0a1,2\n> a\n> b\n"},
+		{[]string{"a", "b"}, nil, "1,2d0\n< a\n< b\n"},
+		{[]string{"a", "b"}, []string{"a", "b", "c", "d"}, "2a3,4\n> c\n> d\n"},
+		{
+			numbers(15, nil),
+			numbers(15, map[int]string{2: "X", 12: "Y"}),
+			"2c2\n< 2\n---\n> X\n12c12\n< 12\n---\n> Y\n",
+		},
+		{
+			[]string{"1", "2", "3", "4", "5", "6", "7"},
+			[]string{"1", "9", "4", "5", "7", "8"},
+			"2,3c2\n< 2\n< 3\n---\n> 9\n6d4\n< 6\n7a6\n> 8\n",
+		},
+	}
+	for i, test := range tests {
+		if have := Normal(test.a, test.b); have != test.want {
+			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.want, have)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// Patching
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(?: (.*))?`)
+
+// ParseUnified parses the hunks of a unified diff. Lines outside of hunks,
+// such as the "---" and "+++" file headers, are ignored, and text following
+// a hunk header is the Section of the hunk. Within a hunk, runs of removed
+// and added lines are paired into Changed lines like SideBySide does.
+func ParseUnified(r io.Reader) ([]Hunk, error) {
+	var hs []Hunk
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<30)
+	line := 0
+	for s.Scan() {
+		line++
+		m := hunkHeader.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		header := line
+		h := Hunk{
+			OldStart: atoi(m[1]),
+			OldLines: atoiDefault(m[2], 1),
+			NewStart: atoi(m[3]),
+			NewLines: atoiDefault(m[4], 1),
+			Section:  m[5],
+		}
+		if h.OldStart < 0 || h.OldLines < 0 || h.NewStart < 0 || h.NewLines < 0 {
+			return nil, fmt.Errorf("diff: line %d: malformed hunk header %q", line, s.Text())
+		}
+		var del, add []string
+		old, new := 0, 0
+		for old < h.OldLines || new < h.NewLines {
+			if !s.Scan() {
+				if err := s.Err(); err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("diff: hunk at line %d: unexpected end of input after %d of %d old and %d of %d new lines",
+					header, old, h.OldLines, new, h.NewLines)
+			}
+			line++
+			text := s.Text()
+			if text == "" {
+				// Some tools strip the space of empty context lines.
+				text = " "
+			}
+			switch text[0] {
+			case ' ':
+				h.Lines = appendChanges(h.Lines, del, add)
+				del, add = del[:0], add[:0]
+				h.Lines = append(h.Lines, SideBySideLine{Left: text[1:], Right: text[1:], Type: NoChange})
+				old++
+				new++
+			case '-':
+				del = append(del, text[1:])
+				old++
+			case '+':
+				add = append(add, text[1:])
+				new++
+			case '\\':
+				continue
+			default:
+				return nil, fmt.Errorf("diff: line %d: malformed hunk line %q", line, text)
+			}
+			if old > h.OldLines || new > h.NewLines {
+				return nil, fmt.Errorf("diff: line %d: hunk has more lines than its header %q announces", line, m[0])
+			}
+		}
+		h.Lines = appendChanges(h.Lines, del, add)
+		hs = append(hs, h)
+	}
+	return hs, s.Err()
+}
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	return atoi(s)
+}
+
+// appendChanges appends the deleted and added lines as SideBySide would.
+func appendChanges(lines []SideBySideLine, del, add []string) []SideBySideLine {
+	for k := 0; k < len(del) || k < len(add); k++ {
+		var line SideBySideLine
+		switch {
+		case k >= len(del):
+			line.Type = Added
+		case k >= len(add):
+			line.Type = Deleted
+		default:
+			line.Type = Changed
+		}
+		if k < len(del) {
+			line.Left = del[k]
+		}
+		if k < len(add) {
+			line.Right = add[k]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ApplyUnified applies hunks to a and returns the result. Like patch, it
+// looks for the old lines of each hunk near the position given in its header,
+// adjusted by the offset at which the previous hunk applied, and fails if
+// they cannot be found.
+func ApplyUnified(a []string, hunks []Hunk) ([]string, error) {
+	var b []string
+	pos, offset := 0, 0
+	for k, h := range hunks {
+		var old, new []string
+		for _, l := range h.Lines {
+			if l.Type != Added {
+				old = append(old, l.Left)
+			}
+			if l.Type != Deleted {
+				new = append(new, l.Right)
+			}
+		}
+		if len(old) != h.OldLines || len(new) != h.NewLines {
+			return nil, fmt.Errorf("diff: hunk %d has %d old and %d new lines, header says %d and %d",
+				k, len(old), len(new), h.OldLines, h.NewLines)
+		}
+		want := h.OldStart
+		if h.OldLines > 0 {
+			want--
+		}
+		at := findLines(a, old, pos, want+offset)
+		if at < 0 {
+			return nil, fmt.Errorf("diff: hunk %d (@@ -%s +%s @@) does not apply",
+				k, hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+		}
+		offset = at - want
+		b = append(b, a[pos:at]...)
+		b = append(b, new...)
+		pos = at + len(old)
+	}
+	return append(b, a[pos:]...), nil
+}
+
+// findLines returns the index closest to want, but not less than first, at
+// which lines occur in a, or -1.
+func findLines(a, lines []string, first, want int) int {
+	last := len(a) - len(lines)
+	for d := 0; want-d >= first || want+d <= last; d++ {
+		if at := want - d; at >= first && at <= last && equalLines(a[at:at+len(lines)], lines) {
+			return at
+		}
+		if at := want + d; at >= first && at <= last && equalLines(a[at:at+len(lines)], lines) {
+			return at
+		}
+	}
+	return -1
+}
@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseUnified(t *testing.T) {
+	const patch = `--- a.txt
++++ b.txt
+@@ -1,3 +1,3 @@
+ a
+-b
++x
+ c
+@@ -10,0 +11 @@
++y
+@@ -20,2 +21 @@ func f()
+-p
+-q
++r
+\ No newline at end of file
+`
+	hs, err := ParseUnified(strings.NewReader(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Hunk{
+		{1, 3, 1, 3, []SideBySideLine{{Left: "a", Right: "a", Type: NoChange}, {Left: "b", Right: "x", Type: Changed}, {Left: "c", Right: "c", Type: NoChange}}, ""},
+		{10, 0, 11, 1, []SideBySideLine{{Right: "y", Type: Added}}, ""},
+		{20, 2, 21, 1, []SideBySideLine{{Left: "p", Right: "r", Type: Changed}, {Left: "q", Type: Deleted}}, "func f()"},
+	}
+	if len(hs) != len(want) {
+		t.Fatalf("want %d hunks, have %d: %v", len(want), len(hs), hs)
+	}
+	for k := range want {
+		if !sameHunk(hs[k], want[k]) {
+			t.Errorf("hunk %d:\nwant %v\nhave %v", k, want[k], hs[k])
+		}
+	}
+}
+
+func sameHunk(a, b Hunk) bool {
+	if a.OldStart != b.OldStart || a.OldLines != b.OldLines || a.NewStart != b.NewStart || a.NewLines != b.NewLines || a.Section != b.Section || len(a.Lines) != len(b.Lines) {
+		return false
+	}
+	for k := range a.Lines {
+		if !reflect.DeepEqual(a.Lines[k], b.Lines[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseUnifiedErrors(t *testing.T) {
+	var tests = []struct {
+		patch string
+		err   string
+	}{
+		{"@@ -1,2 +1,2 @@\n a\n", "diff: hunk at line 1: unexpected end of input after 1 of 2 old and 1 of 2 new lines"},
+		{"@@ -1,2 +1,2 @@\n a\n*b\n", `diff: line 3: malformed hunk line "*b"`},
+		{"@@ -1 +1 @@\n-a\n-b\n", `diff: line 3: hunk has more lines than its header "@@ -1 +1 @@" announces`},
+		{"@@ -99999999999999999999 +1 @@\n", `diff: line 1: malformed hunk header "@@ -99999999999999999999 +1 @@"`},
+	}
+	for i, test := range tests {
+		if _, err := ParseUnified(strings.NewReader(test.patch)); err == nil || err.Error() != test.err {
+			t.Errorf("test %d: want error %q, have %v", i, test.err, err)
+		}
+	}
+}
+
+func TestApplyUnified(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 30, "abcd"), "")
+		b := strings.Split(randString(r, 30, "abcd"), "")
+		context := r.Intn(4)
+		hs, err := ParseUnified(strings.NewReader(Unified(a, b, context)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, err := ApplyUnified(a, hs)
+		if err != nil || !equalLines(have, b) {
+			t.Fatalf("%q, %q, context %d: have %q, %v", a, b, context, have, err)
+		}
+	}
+}
+
+func TestApplyUnifiedOffset(t *testing.T) {
+	a := numbers(20, nil)
+	b := numbers(20, map[int]string{5: "X", 15: "Y"})
+	hs, err := ParseUnified(strings.NewReader(Unified(a, b, 3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The patch still applies after lines have been added above the hunks.
+	shifted := append([]string{"new", "lines"}, a...)
+	have, err := ApplyUnified(shifted, hs)
+	if want := append([]string{"new", "lines"}, b...); err != nil || !equalLines(have, want) {
+		t.Fatalf("want %q\nhave %q, %v", want, have, err)
+	}
+
+	a[4] = "changed"
+	if _, err := ApplyUnified(a, hs); err == nil || err.Error() != "diff: hunk 0 (@@ -2,7 +2,7 @@) does not apply" {
+		t.Errorf("want error for mismatched context, have %v", err)
+	}
+}
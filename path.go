@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Edit graph path
+
+// A Point is a vertex of the edit graph: X elements of the left and Y
+// elements of the right sequence have been consumed.
+type Point struct {
+	X, Y int
+}
+
+// Path returns the path through the edit graph that Diff chooses for data,
+// from (0, 0) to (n, m) for sequences of lengths n and m. Consecutive points
+// are either a diagonal run of equal elements or a single step apart, right
+// (X+1) for a deletion or down (Y+1) for an insertion. data.Common is not
+// called. Like Diff, Path panics if data is inconsistent.
+func Path(data Interface) []Point {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	path := []Point{{0, 0}}
+	if n == 0 || m == 0 {
+		for x := 1; x <= n; x++ {
+			path = append(path, Point{x, 0})
+		}
+		for y := 1; y <= m; y++ {
+			path = append(path, Point{0, y})
+		}
+		return path
+	}
+	vs, err := search(context.Background(), data, n, m, math.MaxInt, true, nil)
+	if err != nil {
+		panic(err)
+	}
+	for _, s := range trace(vs, n, m, len(vs)-1) {
+		x, y, l := s[0], s[1], s[2]
+		if last := path[len(path)-1]; last != (Point{x, y}) {
+			path = append(path, Point{x, y})
+		}
+		if l > 0 {
+			path = append(path, Point{x + l, y + l})
+		}
+	}
+	return path
+}
+
+// DotGraph writes the edit graph of data to w in the DOT language of
+// Graphviz, with the path that Diff chooses drawn in bold red. The vertices
+// are the Points of the graph, placed in a grid for layout with neato -n, and
+// the edges are the steps right and down and, where elements are equal, the
+// diagonal steps between them. As the graph has a vertex for every pair of
+// positions and Equal is called for every pair of elements, DotGraph is only
+// meant for small sequences. data.Common is not called. DotGraph returns the
+// first error writing to w, and an error instead of panicking like Diff if
+// the lengths of data are inconsistent.
+func DotGraph(data Interface, w io.Writer) error {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		return err
+	}
+	onPath := map[[2]Point]bool{}
+	path := Path(data)
+	for k := 1; k < len(path); k++ {
+		p, q := path[k-1], path[k]
+		for p != q {
+			next := p
+			if q.X > p.X {
+				next.X++
+			}
+			if q.Y > p.Y {
+				next.Y++
+			}
+			onPath[[2]Point{p, next}] = true
+			p = next
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph diff {\n\tnode [shape=circle, label=\"\", width=0.1];\n")
+	for y := 0; y <= m; y++ {
+		for x := 0; x <= n; x++ {
+			fmt.Fprintf(bw, "\t\"%d,%d\" [pos=\"%d,%d\"];\n", x, y, 50*x, -50*y)
+		}
+	}
+	edge := func(p, q Point) {
+		fmt.Fprintf(bw, "\t\"%d,%d\" -> \"%d,%d\"", p.X, p.Y, q.X, q.Y)
+		if onPath[[2]Point{p, q}] {
+			bw.WriteString(" [color=red, penwidth=3]")
+		}
+		bw.WriteString(";\n")
+	}
+	for y := 0; y <= m; y++ {
+		for x := 0; x <= n; x++ {
+			p := Point{x, y}
+			if x < n {
+				edge(p, Point{x + 1, y})
+			}
+			if y < m {
+				edge(p, Point{x, y + 1})
+			}
+			if x < n && y < m && data.Equal(x, y) {
+				edge(p, Point{x + 1, y + 1})
+			}
+		}
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		path []Point
+	}{
+		{"", "", []Point{{0, 0}}},
+		{"ab", "", []Point{{0, 0}, {1, 0}, {2, 0}}},
+		{"", "ab", []Point{{0, 0}, {0, 1}, {0, 2}}},
+		{"abc", "abc", []Point{{0, 0}, {3, 3}}},
+		{"abc", "axc", []Point{{0, 0}, {1, 1}, {2, 1}, {2, 2}, {3, 3}}},
+		{"ab", "cd", []Point{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {2, 2}}},
+	}
+	for i, test := range tests {
+		if path := Path(&stringDiff{a: test.a, b: test.b}); !reflect.DeepEqual(path, test.path) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.path, path)
+		}
+	}
+
+	for i, test := range diffTests {
+		d := &stringDiff{a: test.a, b: test.b}
+		path := Path(d)
+		edits := 0
+		for k := 1; k < len(path); k++ {
+			p, q := path[k-1], path[k]
+			switch dx, dy := q.X-p.X, q.Y-p.Y; {
+			case dx == 1 && dy == 0, dx == 0 && dy == 1:
+				edits++
+			case dx > 0 && dx == dy:
+				if test.a[p.X:q.X] != test.b[p.Y:q.Y] {
+					t.Errorf("test %d: %v to %v is not a run of equal elements", i, p, q)
+				}
+			default:
+				t.Errorf("test %d: %v to %v is neither a step nor a run", i, p, q)
+			}
+		}
+		if end := path[len(path)-1]; end != (Point{len(test.a), len(test.b)}) || edits != test.edits {
+			t.Errorf("test %d: path ends at %v with %d edits, want %v with %d", i, end, edits, Point{len(test.a), len(test.b)}, test.edits)
+		}
+		if d.lcsa != nil {
+			t.Errorf("test %d: Common called", i)
+		}
+	}
+}
+
+func TestDotGraph(t *testing.T) {
+	var w strings.Builder
+	if err := DotGraph(&stringDiff{a: "a", b: "ab"}, &w); err != nil {
+		t.Fatal(err)
+	}
+	const want = `digraph diff {
+	node [shape=circle, label="", width=0.1];
+	"0,0" [pos="0,0"];
+	"1,0" [pos="50,0"];
+	"0,1" [pos="0,-50"];
+	"1,1" [pos="50,-50"];
+	"0,2" [pos="0,-100"];
+	"1,2" [pos="50,-100"];
+	"0,0" -> "1,0";
+	"0,0" -> "0,1";
+	"0,0" -> "1,1" [color=red, penwidth=3];
+	"1,0" -> "1,1";
+	"0,1" -> "1,1";
+	"0,1" -> "0,2";
+	"1,1" -> "1,2" [color=red, penwidth=3];
+	"0,2" -> "1,2";
+}
+`
+	if w.String() != want {
+		t.Errorf("want %s\nhave %s", want, w.String())
+	}
+}
+
+func TestDotGraphError(t *testing.T) {
+	broken := errors.New("broken")
+	if err := DotGraph(&stringDiff{a: "abc", b: "abd"}, errWriter{broken}); err != broken {
+		t.Errorf("want %v, have %v", broken, err)
+	}
+	var w strings.Builder
+	if err := DotGraph(&badDiff{n: -1}, &w); err != ErrNegativeLength || w.Len() > 0 {
+		t.Errorf("want %v and no output, have %v, %q", ErrNegativeLength, err, w.String())
+	}
+}
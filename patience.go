@@ -0,0 +1,119 @@
+package diff
+
+import "sort"
+
+// Patience diff
+
+// DiffPatience is like Diff but uses the patience diff strategy: elements
+// that occur exactly once in each sequence are matched first, keeping the
+// longest run of such matches that is in order on both sides, and the gaps
+// between these anchors are diffed recursively, falling back to Diff where no
+// unique elements remain. The result is a common subsequence that need not be
+// the longest, but which tends to align on distinctive lines rather than on
+// braces and blank lines. data.Common is called under the same contract as
+// for Diff, and the returned length of the edit script is that of the
+// reported subsequence.
+//
+// As the Interface only compares elements across the sequences, finding the
+// unique elements of a gap calls Equal for every pair of elements in it.
+func DiffPatience(data Interface) int {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	p := &patience{data: data, runs: runs{data: data}}
+	p.compare(0, n, 0, m)
+	p.runs.flush(n, m)
+	return n + m - 2*p.runs.total
+}
+
+type patience struct {
+	data Interface
+	runs runs
+}
+
+func (p *patience) compare(i0, i1, j0, j1 int) {
+	n := 0
+	for i0+n < i1 && j0+n < j1 && p.data.Equal(i0+n, j0+n) {
+		n++
+	}
+	p.runs.add(i0, j0, n)
+	i0 += n
+	j0 += n
+
+	s := 0
+	for i1-s > i0 && j1-s > j0 && p.data.Equal(i1-s-1, j1-s-1) {
+		s++
+	}
+	i1 -= s
+	j1 -= s
+
+	anchors := longestIncreasing(p.unique(i0, i1, j0, j1))
+	if len(anchors) == 0 {
+		Diff(&window{p.data, &p.runs, i0, i1, j0, j1})
+	} else {
+		i, j := i0, j0
+		for _, a := range anchors {
+			p.compare(i, a[0], j, a[1])
+			p.runs.add(a[0], a[1], 1)
+			i, j = a[0]+1, a[1]+1
+		}
+		p.compare(i, i1, j, j1)
+	}
+	p.runs.add(i1, j1, s)
+}
+
+// unique returns the pairs {i, j} of elements of left[i0:i1] and right[j0:j1]
+// that are equal and occur only once in each, ordered by i.
+func (p *patience) unique(i0, i1, j0, j1 int) [][2]int {
+	if i0 == i1 || j0 == j1 {
+		return nil
+	}
+	countA := make([]int, i1-i0)
+	matchA := make([]int, i1-i0)
+	countB := make([]int, j1-j0)
+	for i := i0; i < i1; i++ {
+		for j := j0; j < j1; j++ {
+			if p.data.Equal(i, j) {
+				countA[i-i0]++
+				matchA[i-i0] = j
+				countB[j-j0]++
+			}
+		}
+	}
+	var pairs [][2]int
+	for i, c := range countA {
+		if j := matchA[i]; c == 1 && countB[j-j0] == 1 {
+			pairs = append(pairs, [2]int{i0 + i, j})
+		}
+	}
+	return pairs
+}
+
+// longestIncreasing returns the longest subsequence of pairs, which must be
+// ordered by their first and have distinct second elements, that is also
+// ordered by their second elements.
+func longestIncreasing(pairs [][2]int) [][2]int {
+	if len(pairs) == 0 {
+		return nil
+	}
+	var tails []int // tails[l]: index of the smallest end of a subsequence of length l+1
+	prev := make([]int, len(pairs))
+	for k, p := range pairs {
+		l := sort.Search(len(tails), func(t int) bool { return pairs[tails[t]][1] > p[1] })
+		prev[k] = -1
+		if l > 0 {
+			prev[k] = tails[l-1]
+		}
+		if l == len(tails) {
+			tails = append(tails, k)
+		} else {
+			tails[l] = k
+		}
+	}
+	seq := make([][2]int, len(tails))
+	for l, k := len(tails)-1, tails[len(tails)-1]; l >= 0; l, k = l-1, prev[k] {
+		seq[l] = pairs[k]
+	}
+	return seq
+}
@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffPatience(t *testing.T) {
+	testDiff(t, DiffPatience)
+}
+
+// checkCommon reports whether the Common calls recorded in d describe a
+// common subsequence of d.a and d.b of length (len(a)+len(b)-edits)/2.
+func checkCommon(t *testing.T, d *lineDiff, edits int) {
+	t.Helper()
+	x, y, total := 0, 0, 0
+	for k, c := range d.common {
+		i, j, n := c[0], c[1], c[2]
+		if i < x || j < y || (k > 0 && i == x && j == y) {
+			t.Fatalf("Common%v out of order", c)
+		}
+		if !reflect.DeepEqual(d.a[i:i+n], d.b[j:j+n]) {
+			t.Fatalf("Common%v: %q != %q", c, d.a[i:i+n], d.b[j:j+n])
+		}
+		x, y, total = i+n, j+n, total+n
+	}
+	if x != len(d.a) || y != len(d.b) {
+		t.Fatalf("last Common call %v does not end at (%d, %d)", d.common[len(d.common)-1], len(d.a), len(d.b))
+	}
+	if len(d.a)+len(d.b)-2*total != edits {
+		t.Fatalf("common subsequence of length %d does not match %d edits", total, edits)
+	}
+}
+
+func TestDiffPatienceRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 20, "abcdefgh"), "")
+		b := strings.Split(randString(r, 20, "abcdefgh"), "")
+		d := &lineDiff{a: a, b: b}
+		checkCommon(t, d, DiffPatience(d))
+	}
+}
+
+// functions of a source file, to be reordered.
+var functions = map[string]string{
+	"v": "func v() {\n\treturn 2\n}\n",
+	"w": "func w() {\n\tstep()\n}\n",
+	"x": "func x() {\n\tif a {\n\t\treturn 1\n\t}\n\treturn 2\n}\n",
+	"y": "func y() {\n\tfor {\n\t\tstep()\n\t}\n\treturn 3\n}\n",
+	"z": "func z() {\n\tif b {\n\t\tpanic(1)\n\t}\n}\n",
+}
+
+// source returns the lines of a file with the named functions in order.
+func source(names ...string) []string {
+	var fns []string
+	for _, name := range names {
+		fns = append(fns, functions[name])
+	}
+	return strings.Split(strings.Join(fns, "\n"), "\n")
+}
+
+// changeGroups returns the number of separate regions of changes.
+func changeGroups(d *lineDiff) int {
+	groups, x, y := 0, 0, 0
+	for _, c := range d.common {
+		if c[0] > x || c[1] > y {
+			groups++
+		}
+		x, y = c[0]+c[2], c[1]+c[2]
+	}
+	return groups
+}
+
+func TestDiffPatienceReorder(t *testing.T) {
+	a, b := source("x", "z", "v", "y", "w"), source("w", "y", "v", "z", "x")
+
+	myers := &lineDiff{a: a, b: b}
+	Diff(myers)
+	patience := &lineDiff{a: a, b: b}
+	checkCommon(t, patience, DiffPatience(patience))
+
+	// Myers matches braces and return statements across unrelated
+	// functions, while patience diff keeps the function y together and
+	// treats the others as moved.
+	if m, p := changeGroups(myers), changeGroups(patience); m != 6 || p != 2 {
+		t.Errorf("want 6 change groups for Myers and 2 for patience diff, have %d and %d", m, p)
+	}
+	if want := [3]int{15, 2, 9}; patience.common[0] != want {
+		t.Errorf("want common part %v, have %v", want, patience.common[0])
+	}
+}
@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Reading lines
+
+// ReadLines reads r to the end and splits its contents into lines after each
+// "\n". If keepEnds is false, the "\n" is removed from each line. A last line
+// without "\n" is returned as is, and no empty line follows a final "\n".
+func ReadLines(r io.Reader, keepEnds bool) ([]string, error) {
+	var lines []string
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			if !keepEnds {
+				line = strings.TrimSuffix(line, "\n")
+			}
+			lines = append(lines, line)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+}
+
+// SplitLines splits s into lines after each "\r\n", "\n" or "\r", which
+// are removed. As with ReadLines, no empty line follows a final line ending.
+func SplitLines(s string) []string {
+	var lines []string
+	for s != "" {
+		i := strings.IndexAny(s, "\r\n")
+		if i < 0 {
+			lines = append(lines, s)
+			break
+		}
+		lines = append(lines, s[:i])
+		if strings.HasPrefix(s[i:], "\r\n") {
+			i++
+		}
+		s = s[i+1:]
+	}
+	return lines
+}
+
+// DiffReaders reads the lines of a and b with ReadLines, without their "\n",
+// and returns their side-by-side diff.
+func DiffReaders(a, b io.Reader) ([]SideBySideLine, error) {
+	return DiffReadersEnds(a, b, false)
+}
+
+// DiffReadersEnds is like DiffReaders but keeps the "\n" of each line if
+// keepEnds is true, as ReadLines does, so that a last line without "\n"
+// differs from the same line with one.
+func DiffReadersEnds(a, b io.Reader, keepEnds bool) ([]SideBySideLine, error) {
+	la, err := ReadLines(a, keepEnds)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := ReadLines(b, keepEnds)
+	if err != nil {
+		return nil, err
+	}
+	return SideBySide(la, lb), nil
+}
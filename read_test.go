@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadLines(t *testing.T) {
+	var tests = []struct {
+		in       string
+		keepEnds bool
+		lines    []string
+	}{
+		{"", false, nil},
+		{"a\nb\n", false, []string{"a", "b"}},
+		{"a\nb", false, []string{"a", "b"}},
+		{"a\n\n", false, []string{"a", ""}},
+		{"a\r\nb", false, []string{"a\r", "b"}},
+		{"a\nb\n", true, []string{"a\n", "b\n"}},
+		{"a\nb", true, []string{"a\n", "b"}},
+	}
+	for i, test := range tests {
+		lines, err := ReadLines(iotest.OneByteReader(strings.NewReader(test.in)), test.keepEnds)
+		if err != nil || !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("test %d:\nwant %q\nhave %q, %v\n", i, test.lines, lines, err)
+		}
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	var tests = []struct {
+		in    string
+		lines []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\nb\n", []string{"a", "b"}},
+		{"a\r\nb\r\n", []string{"a", "b"}},
+		{"a\rb\r", []string{"a", "b"}},
+		{"a\r\n\r\nb\n\rc", []string{"a", "", "b", "", "c"}},
+		{"\n", []string{""}},
+	}
+	for i, test := range tests {
+		if lines := SplitLines(test.in); !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.lines, lines)
+		}
+	}
+}
+
+func TestDiffReaders(t *testing.T) {
+	lines, err := DiffReaders(strings.NewReader("a\nb\n"), strings.NewReader("a\nc"))
+	want := []SideBySideLine{
+		{Left: "a", Right: "a", Type: NoChange},
+		{Left: "b", Right: "c", Type: Changed},
+	}
+	if err != nil || !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v, %v", want, lines, err)
+	}
+
+	for _, keepEnds := range []bool{false, true} {
+		lines, err := DiffReadersEnds(strings.NewReader("a\nb\n"), strings.NewReader("a\nb"), keepEnds)
+		want := []SideBySideLine{
+			{Left: "a", Right: "a", Type: NoChange},
+			{Left: "b", Right: "b", Type: NoChange},
+		}
+		if keepEnds {
+			want = []SideBySideLine{
+				{Left: "a\n", Right: "a\n", Type: NoChange},
+				{Left: "b\n", Right: "b", Type: Changed},
+			}
+		}
+		if err != nil || !reflect.DeepEqual(lines, want) {
+			t.Errorf("keepEnds %v: want %v\nhave %v, %v", keepEnds, want, lines, err)
+		}
+	}
+
+	broken := errors.New("broken")
+	if _, err := DiffReadersEnds(strings.NewReader("a"), iotest.ErrReader(broken), true); err != broken {
+		t.Errorf("keepEnds: want %v, have %v", broken, err)
+	}
+	if _, err := DiffReaders(strings.NewReader("a"), iotest.ErrReader(broken)); err != broken {
+		t.Errorf("want %v, have %v", broken, err)
+	}
+	if _, err := DiffReaders(iotest.TimeoutReader(strings.NewReader("a\n")), strings.NewReader("")); err != iotest.ErrTimeout {
+		t.Errorf("want %v, have %v", iotest.ErrTimeout, err)
+	}
+}
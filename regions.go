@@ -0,0 +1,34 @@
+package diff
+
+// Change regions
+
+// A Region is a maximal run of changes between two sequences: the lines
+// old[OldStart:OldEnd] are replaced by new[NewStart:NewEnd]. Either range may
+// be empty, but not both.
+type Region struct {
+	OldStart, OldEnd int
+	NewStart, NewEnd int
+}
+
+// Regions returns the regions that differ between a and b, from top to
+// bottom. Deletions and additions that are adjacent form a single region, and
+// the regions are separated by at least one unchanged line on both sides, so
+// together with the unchanged runs between them they tile a and b.
+func Regions(a, b []string) []Region {
+	d := &regions{lineData: lineData{a, b}}
+	Diff(d)
+	return d.regions
+}
+
+type regions struct {
+	lineData
+	i, j    int
+	regions []Region
+}
+
+func (d *regions) Common(i, j, n int) {
+	if i > d.i || j > d.j {
+		d.regions = append(d.regions, Region{d.i, i, d.j, j})
+	}
+	d.i, d.j = i+n, j+n
+}
@@ -0,0 +1,26 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegions(t *testing.T) {
+	var tests = []struct {
+		a, b []string
+		want []Region
+	}{
+		{nil, nil, nil},
+		{[]string{"a", "b"}, []string{"a", "b"}, nil},
+		{nil, []string{"a"}, []Region{{0, 0, 0, 1}}},
+		{[]string{"a"}, nil, []Region{{0, 1, 0, 0}}},
+		{[]string{"a", "b", "c"}, []string{"a", "x", "c"}, []Region{{1, 2, 1, 2}}},
+		{[]string{"a", "b", "c", "d"}, []string{"x", "b", "c", "y", "z"}, []Region{{0, 1, 0, 1}, {3, 4, 3, 5}}},
+		{[]string{"a", "b", "c"}, []string{"b", "c", "d"}, []Region{{0, 1, 0, 0}, {3, 3, 2, 3}}},
+	}
+	for i, test := range tests {
+		if have := Regions(test.a, test.b); !reflect.DeepEqual(have, test.want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.want, have)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package diff
+
+// Rune diff
+
+// RuneDiff returns the edit script from a to b, treating the strings as
+// sequences of runes: the indices and lengths of the ops count runes, not
+// bytes. Invalid UTF-8 is decoded as in a conversion to []rune, each invalid
+// byte becoming utf8.RuneError.
+func RuneDiff(a, b string) []Op {
+	return EditScript(runeData{[]rune(a), []rune(b)})
+}
+
+// DiffRunes is Diff for two slices of runes, passing each part of the common
+// subsequence to onCommon as data.Common would receive it. onCommon may be nil
+// if only the length of the edit script is needed.
+func DiffRunes(a, b []rune, onCommon func(i, j, n int)) int {
+	if onCommon == nil {
+		return Diff(runeData{a, b})
+	}
+	return Diff(runeCommon{runeData{a, b}, onCommon})
+}
+
+// runeCommon is runeData with a function to which Common is passed on.
+type runeCommon struct {
+	runeData
+	common func(i, j, n int)
+}
+
+func (d runeCommon) Common(i, j, n int) { d.common(i, j, n) }
+
+// runeData is the Interface of two slices of runes for callers that are only
+// interested in the result of Diff or EditScript.
+type runeData struct {
+	a []rune
+	b []rune
+}
+
+func (d runeData) Lengths() (int, int) { return len(d.a), len(d.b) }
+func (d runeData) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d runeData) Common(i, j, n int)  {}
@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuneDiff(t *testing.T) {
+	var tests = []struct {
+		a   string
+		b   string
+		ops []Op
+	}{
+		{"", "", nil},
+		{"日本語", "日本語", []Op{{NoChange, 0, 0, 3, nil}}},
+		{"日本語", "日本人", []Op{{NoChange, 0, 0, 2, nil}, {Deleted, 2, 2, 1, nil}, {Added, 3, 2, 1, nil}}},
+		{"héllo", "hello", []Op{{NoChange, 0, 0, 1, nil}, {Deleted, 1, 1, 1, nil}, {Added, 2, 1, 1, nil}, {NoChange, 2, 2, 3, nil}}},
+		// Both é and è share their first byte, which must not be matched.
+		{"é", "è", []Op{{Deleted, 0, 0, 1, nil}, {Added, 1, 0, 1, nil}}},
+		{"a\xffb", "ab", []Op{{NoChange, 0, 0, 1, nil}, {Deleted, 1, 1, 1, nil}, {NoChange, 2, 1, 1, nil}}},
+	}
+	for i, test := range tests {
+		ops := RuneDiff(test.a, test.b)
+		if !reflect.DeepEqual(ops, test.ops) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.ops, ops)
+		}
+	}
+}
+
+func TestDiffRunes(t *testing.T) {
+	var tests = []struct {
+		a, b   string
+		edits  int
+		common [][3]int
+	}{
+		{"", "", 0, [][3]int{{0, 0, 0}}},
+		{"日本語", "日本語", 0, [][3]int{{0, 0, 3}}},
+		{"日本語", "日本人", 2, [][3]int{{0, 0, 2}, {3, 3, 0}}},
+		// Compared by bytes, é and è would share their first byte.
+		{"éa", "èa", 2, [][3]int{{1, 1, 1}}},
+		{"aé", "éb", 2, [][3]int{{1, 0, 1}, {2, 2, 0}}},
+	}
+	for i, test := range tests {
+		var common [][3]int
+		edits := DiffRunes([]rune(test.a), []rune(test.b), func(i, j, n int) {
+			common = append(common, [3]int{i, j, n})
+		})
+		if edits != test.edits || !reflect.DeepEqual(common, test.common) {
+			t.Errorf("test %d:\nwant %d, %v\nhave %d, %v\n", i, test.edits, test.common, edits, common)
+		}
+		if edits := DiffRunes([]rune(test.a), []rune(test.b), nil); edits != test.edits {
+			t.Errorf("test %d: want %d edits without onCommon, have %d", i, test.edits, edits)
+		}
+	}
+}
@@ -0,0 +1,498 @@
+package diff
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SideBySideContext is like SideBySide but only keeps context unchanged lines
+// around each change. Each run of unchanged lines left out is replaced by a
+// single line with Type==Skipped and Count set to the length of the run.
+func SideBySideContext(a, b []string, context int) []SideBySideLine {
+	if context < 0 {
+		context = 0
+	}
+	lines := SideBySide(a, b)
+	var out []SideBySideLine
+	for r := 0; r < len(lines); {
+		if lines[r].Type != NoChange {
+			out = append(out, lines[r])
+			r++
+			continue
+		}
+		end := r
+		for end < len(lines) && lines[end].Type == NoChange {
+			end++
+		}
+		keepStart, keepEnd := context, context // around the changes before and after
+		if r == 0 {
+			keepStart = 0
+		}
+		if end == len(lines) {
+			keepEnd = 0
+		}
+		if n := end - r - keepStart - keepEnd; n > 0 {
+			out = append(out, lines[r:r+keepStart]...)
+			out = append(out, SideBySideLine{Type: Skipped, Count: n})
+			out = append(out, lines[end-keepEnd:end]...)
+		} else {
+			out = append(out, lines[r:end]...)
+		}
+		r = end
+	}
+	return out
+}
+
+// SideBySideMoves is like SideBySide but detects blocks of lines that were
+// moved: a run of Deleted lines that exactly matches a run of Added lines
+// elsewhere is reported with Type==Moved on both ends, the lines at the old
+// place carrying Left and those at the new place Right. Both runs share a
+// MoveID, counting from 1. Runs of blank lines are not considered moved.
+func SideBySideMoves(a, b []string) []SideBySideLine {
+	lines := SideBySide(a, b)
+	var deleted, added [][2]int // runs as [start, end) in lines
+	for r := 0; r < len(lines); {
+		end := r + 1
+		for end < len(lines) && lines[end].Type == lines[r].Type {
+			end++
+		}
+		if !blank(lines[r:end]) {
+			switch lines[r].Type {
+			case Deleted:
+				deleted = append(deleted, [2]int{r, end})
+			case Added:
+				added = append(added, [2]int{r, end})
+			}
+		}
+		r = end
+	}
+	id := 0
+	for _, del := range deleted {
+		for k, add := range added {
+			if add[1]-add[0] != del[1]-del[0] || lines[add[0]].Type == Moved {
+				continue
+			}
+			if !sameText(lines[del[0]:del[1]], lines[add[0]:add[1]]) {
+				continue
+			}
+			id++
+			for r := del[0]; r < del[1]; r++ {
+				lines[r].Type, lines[r].MoveID = Moved, id
+			}
+			for r := add[0]; r < add[1]; r++ {
+				lines[r].Type, lines[r].MoveID = Moved, id
+			}
+			added = append(added[:k], added[k+1:]...)
+			break
+		}
+	}
+	return lines
+}
+
+// blank reports whether all lines are blank on both sides.
+func blank(lines []SideBySideLine) bool {
+	for _, l := range lines {
+		if strings.TrimSpace(l.Left) != "" || strings.TrimSpace(l.Right) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sameText reports whether the Left texts of del equal the Right texts of add.
+func sameText(del, add []SideBySideLine) bool {
+	for k := range del {
+		if del[k].Left != add[k].Right {
+			return false
+		}
+	}
+	return true
+}
+
+// ReverseLines returns the side-by-side diff from right to left for the
+// side-by-side diff lines from left to right.
+func ReverseLines(lines []SideBySideLine) []SideBySideLine {
+	rev := make([]SideBySideLine, len(lines))
+	for k, l := range lines {
+		l.Left, l.Right = l.Right, l.Left
+		l.LeftSpans, l.RightSpans = l.RightSpans, l.LeftSpans
+		switch l.Type {
+		case Added:
+			l.Type = Deleted
+		case Deleted:
+			l.Type = Added
+		}
+		rev[k] = l
+	}
+	return rev
+}
+
+// SideBySideFromOps returns the side-by-side diff of a and b that the edit
+// script ops from a to b describes, without diffing them again, so that a
+// script that has been post-processed, for example by Cleanup, can be shown.
+// The lines are taken from a and b, not from the Lines of the ops. As in
+// SideBySide, the lines deleted and added between two unchanged lines are
+// paired into Changed lines, so for the ops EditScript returns the result is
+// that of SideBySide. The ops must lie within a and b.
+func SideBySideFromOps(a, b []string, ops []Op) []SideBySideLine {
+	var lines []SideBySideLine
+	var del, add []string
+	for _, op := range ops {
+		switch op.Kind {
+		case Deleted:
+			del = append(del, a[op.FromI:op.FromI+op.Len]...)
+		case Added:
+			add = append(add, b[op.FromJ:op.FromJ+op.Len]...)
+		case NoChange:
+			lines = appendChanges(lines, del, add)
+			del, add = del[:0], add[:0]
+			for k := 0; k < op.Len; k++ {
+				lines = append(lines, SideBySideLine{Left: a[op.FromI+k], Right: b[op.FromJ+k], Type: NoChange})
+			}
+		}
+	}
+	return appendChanges(lines, del, add)
+}
+
+// An OriginLine is a line of a side-by-side diff together with the 0-based
+// indices of its Left and Right lines in the sequences diffed, or -1 for a
+// side that is absent, such as the right one of a Deleted line.
+type OriginLine struct {
+	Line       SideBySideLine `json:"line"`
+	LeftIndex  int            `json:"leftIndex"`
+	RightIndex int            `json:"rightIndex"`
+}
+
+// SideBySideOrigins is like SideBySide but also returns the index of each
+// line in a and b, to map the rows shown back to their sources.
+func SideBySideOrigins(a, b []string) []OriginLine {
+	lines := SideBySide(a, b)
+	origins := make([]OriginLine, len(lines))
+	i, j := 0, 0
+	for k, l := range lines {
+		o := OriginLine{Line: l, LeftIndex: -1, RightIndex: -1}
+		if l.Type != Added {
+			o.LeftIndex = i
+			i++
+		}
+		if l.Type != Deleted {
+			o.RightIndex = j
+			j++
+		}
+		origins[k] = o
+	}
+	return origins
+}
+
+// A Span is the byte range Start to End of a line in a side-by-side diff.
+type Span struct {
+	Start   int  `json:"start"`
+	End     int  `json:"end"`
+	Changed bool `json:"changed,omitempty"` // The range has no counterpart in the other line.
+}
+
+// SideBySideDetailed is like SideBySide but also diffs the runes of the two
+// sides of each Changed line, setting LeftSpans and RightSpans. The spans of
+// a line are ordered and cover it without overlapping; adjacent spans differ
+// in Changed.
+func SideBySideDetailed(a, b []string) []SideBySideLine {
+	lines := SideBySide(a, b)
+	for k, l := range lines {
+		if l.Type == Changed {
+			lines[k].LeftSpans, lines[k].RightSpans = spans(l.Left, l.Right)
+		}
+	}
+	return lines
+}
+
+// spans returns the spans of x and y according to their rune diff.
+func spans(x, y string) (xs, ys []Span) {
+	xo, yo := runeOffsets(x), runeOffsets(y)
+	for _, op := range RuneDiff(x, y) {
+		switch op.Kind {
+		case NoChange:
+			xs = appendSpan(xs, xo[op.FromI], xo[op.FromI+op.Len], false)
+			ys = appendSpan(ys, yo[op.FromJ], yo[op.FromJ+op.Len], false)
+		case Deleted:
+			xs = appendSpan(xs, xo[op.FromI], xo[op.FromI+op.Len], true)
+		case Added:
+			ys = appendSpan(ys, yo[op.FromJ], yo[op.FromJ+op.Len], true)
+		}
+	}
+	return xs, ys
+}
+
+// runeOffsets returns the byte offsets of the runes of s, followed by len(s).
+// Like a conversion to []rune, it counts each byte of invalid UTF-8 as a rune.
+func runeOffsets(s string) []int {
+	var offsets []int
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(s))
+}
+
+// appendSpan appends the span from start to end to spans, merging it with the
+// last span if that is adjacent and has the same Changed.
+func appendSpan(spans []Span, start, end int, changed bool) []Span {
+	if start == end {
+		return spans
+	}
+	if n := len(spans); n > 0 && spans[n-1].End == start && spans[n-1].Changed == changed {
+		spans[n-1].End = end
+		return spans
+	}
+	return append(spans, Span{start, end, changed})
+}
+
+// ExpandTabs returns lines with the tabs in Left and Right replaced by spaces
+// up to the next tab stop, with tab stops every tabWidth columns and each
+// rune taking one column. LeftSpans and RightSpans are adjusted to the
+// expanded text. If tabWidth is less than 1, the lines are returned as is.
+func ExpandTabs(lines []SideBySideLine, tabWidth int) []SideBySideLine {
+	out := make([]SideBySideLine, len(lines))
+	for k, l := range lines {
+		if tabWidth >= 1 {
+			l.Left, l.LeftSpans = expandTabs(l.Left, l.LeftSpans, tabWidth)
+			l.Right, l.RightSpans = expandTabs(l.Right, l.RightSpans, tabWidth)
+		}
+		out[k] = l
+	}
+	return out
+}
+
+func expandTabs(s string, spans []Span, tabWidth int) (string, []Span) {
+	if !strings.Contains(s, "\t") {
+		return s, spans
+	}
+	var b strings.Builder
+	offsets := make([]int, len(s)+1) // offsets[i] is the offset of s[i:] in the result
+	col := 0
+	for i := 0; i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		for k := i; k < i+size; k++ {
+			offsets[k] = b.Len()
+		}
+		switch c {
+		case '\t':
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case '\n':
+			b.WriteByte('\n')
+			col = 0
+		default:
+			b.WriteString(s[i : i+size])
+			col++
+		}
+		i += size
+	}
+	offsets[len(s)] = b.Len()
+	var expanded []Span
+	for _, sp := range spans {
+		expanded = append(expanded, Span{offsets[sp.Start], offsets[sp.End], sp.Changed})
+	}
+	return b.String(), expanded
+}
+
+// BlockDiff splits a and b into blocks separated by sep, for example "\n\n"
+// for paragraphs, and returns the side-by-side diff of the blocks. Separators
+// at the start or end of a or b do not produce empty blocks. If sep is empty,
+// a and b are single blocks.
+func BlockDiff(a, b string, sep string) []SideBySideLine {
+	return SideBySide(splitBlocks(a, sep), splitBlocks(b, sep))
+}
+
+func splitBlocks(s, sep string) []string {
+	if sep != "" {
+		for strings.HasPrefix(s, sep) {
+			s = s[len(sep):]
+		}
+		for strings.HasSuffix(s, sep) {
+			s = s[:len(s)-len(sep)]
+		}
+	}
+	if s == "" {
+		return nil
+	}
+	if sep == "" {
+		return []string{s}
+	}
+	return strings.Split(s, sep)
+}
+
+// Wrap splits the lines whose Left or Right is longer than width runes into
+// several lines, all of the same Type, with both sides wrapped at the same
+// row and the shorter side continued with empty text. All but the first of
+// the lines a line is split into have Continuation set. LeftSpans and
+// RightSpans are split along with the text. If width is less than 1, the
+// lines are returned as is.
+func Wrap(lines []SideBySideLine, width int) []SideBySideLine {
+	if width < 1 {
+		return append([]SideBySideLine(nil), lines...)
+	}
+	var out []SideBySideLine
+	for _, l := range lines {
+		left, right := wrapBounds(l.Left, width), wrapBounds(l.Right, width)
+		for k := 0; k < max(len(left), len(right), 1); k++ {
+			row := l
+			row.Left, row.LeftSpans = wrapPart(l.Left, l.LeftSpans, left, k)
+			row.Right, row.RightSpans = wrapPart(l.Right, l.RightSpans, right, k)
+			row.Continuation = l.Continuation || k > 0
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// wrapBounds returns the byte ranges of the parts of s of width runes each.
+func wrapBounds(s string, width int) [][2]int {
+	var bounds [][2]int
+	start, n := 0, 0
+	for i := range s {
+		if n == width {
+			bounds = append(bounds, [2]int{start, i})
+			start, n = i, 0
+		}
+		n++
+	}
+	if start < len(s) {
+		bounds = append(bounds, [2]int{start, len(s)})
+	}
+	return bounds
+}
+
+// wrapPart returns the k-th part of s and the parts of spans within it.
+func wrapPart(s string, spans []Span, bounds [][2]int, k int) (string, []Span) {
+	if k >= len(bounds) {
+		return "", nil
+	}
+	start, end := bounds[k][0], bounds[k][1]
+	var part []Span
+	for _, sp := range spans {
+		if lo, hi := max(sp.Start, start), min(sp.End, end); lo < hi {
+			part = append(part, Span{lo - start, hi - start, sp.Changed})
+		}
+	}
+	return s[start:end], part
+}
+
+// SideBySideIgnoreBlank is like SideBySide but ignores blank lines, diffing
+// only the others. Blank lines are shown as NoChange lines where they occur,
+// those of a and b at the same place paired up as far as they go, and the
+// other side empty where one side has more of them. Which side such a line
+// comes from is told by SideBySideIgnoreBlankOrigins.
+func SideBySideIgnoreBlank(a, b []string) []SideBySideLine {
+	origins := SideBySideIgnoreBlankOrigins(a, b)
+	if origins == nil {
+		return nil
+	}
+	lines := make([]SideBySideLine, len(origins))
+	for k, o := range origins {
+		lines[k] = o.Line
+	}
+	return lines
+}
+
+// SideBySideIgnoreBlankOrigins is like SideBySideIgnoreBlank but also returns
+// the index of each line in a and b, or -1 for a side without a line, such as
+// the right one of a blank line only a has.
+func SideBySideIgnoreBlankOrigins(a, b []string) []OriginLine {
+	var fa, fb []string
+	for _, l := range a {
+		if strings.TrimSpace(l) != "" {
+			fa = append(fa, l)
+		}
+	}
+	for _, l := range b {
+		if strings.TrimSpace(l) != "" {
+			fb = append(fb, l)
+		}
+	}
+	var out []OriginLine
+	i, j := 0, 0
+	blankA := func() bool { return i < len(a) && strings.TrimSpace(a[i]) == "" }
+	blankB := func() bool { return j < len(b) && strings.TrimSpace(b[j]) == "" }
+	// blanks adds the blank lines of a and b at the current place as far
+	// as they pair up, then those left before the next line of a if na and
+	// before the next line of b if nb.
+	blanks := func(na, nb bool) {
+		for ; blankA() && blankB(); i, j = i+1, j+1 {
+			out = append(out, OriginLine{SideBySideLine{Left: a[i], Right: b[j], Type: NoChange}, i, j})
+		}
+		for ; na && blankA(); i++ {
+			out = append(out, OriginLine{SideBySideLine{Left: a[i], Type: NoChange}, i, -1})
+		}
+		for ; nb && blankB(); j++ {
+			out = append(out, OriginLine{SideBySideLine{Right: b[j], Type: NoChange}, -1, j})
+		}
+	}
+	for _, l := range SideBySide(fa, fb) {
+		blanks(l.Type != Added, l.Type != Deleted)
+		o := OriginLine{Line: l, LeftIndex: -1, RightIndex: -1}
+		if l.Type != Added {
+			o.LeftIndex = i
+			i++
+		}
+		if l.Type != Deleted {
+			o.RightIndex = j
+			j++
+		}
+		out = append(out, o)
+	}
+	blanks(true, true)
+	return out
+}
+
+// A Block is a run of side-by-side diff lines of the same Type. Left and
+// Right hold the texts of the lines in order, except that a block of Added
+// lines has no Left and one of Deleted lines no Right. A block of Moved lines
+// is one end of a move, with the MoveID of its lines, and holds only Left
+// for the old end and only Right for the new one. A block of Skipped lines
+// holds no texts but, in Count, the number of unchanged lines left out.
+type Block struct {
+	Type   ChangeType
+	Left   []string
+	Right  []string
+	Count  int
+	MoveID int
+}
+
+// CoalesceRuns groups lines into blocks of consecutive lines of the same
+// Type, and for Moved lines also of the same MoveID, keeping only the texts
+// each line has, so that the blocks hold the lines of both sides in order.
+func CoalesceRuns(lines []SideBySideLine) []Block {
+	var blocks []Block
+	for r := 0; r < len(lines); {
+		end := r + 1
+		for end < len(lines) && lines[end].Type == lines[r].Type && lines[end].MoveID == lines[r].MoveID {
+			end++
+		}
+		b := Block{Type: lines[r].Type, MoveID: lines[r].MoveID}
+		left, right := b.Type != Added, b.Type != Deleted
+		switch b.Type {
+		case Skipped:
+			left, right = false, false
+		case Moved:
+			// The lines of one end of a move are not all blank, and only
+			// those of the old end have a Left.
+			left = false
+			for _, l := range lines[r:end] {
+				left = left || l.Left != ""
+			}
+			right = !left
+		}
+		for _, l := range lines[r:end] {
+			if left {
+				b.Left = append(b.Left, l.Left)
+			}
+			if right {
+				b.Right = append(b.Right, l.Right)
+			}
+			b.Count += l.Count
+		}
+		blocks = append(blocks, b)
+		r = end
+	}
+	return blocks
+}
@@ -0,0 +1,406 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSideBySideContext(t *testing.T) {
+	var tests = []struct {
+		a       []string
+		b       []string
+		context int
+		lines   []SideBySideLine
+	}{{
+		nil,
+		nil,
+		1,
+		nil,
+	}, {
+		numbers(3, nil),
+		numbers(3, nil),
+		1,
+		[]SideBySideLine{{Type: Skipped, Count: 3}},
+	}, {
+		numbers(10, nil),
+		numbers(10, map[int]string{5: "X"}),
+		1,
+		[]SideBySideLine{
+			{Type: Skipped, Count: 3},
+			{Left: "4", Right: "4", Type: NoChange},
+			{Left: "5", Right: "X", Type: Changed},
+			{Left: "6", Right: "6", Type: NoChange},
+			{Type: Skipped, Count: 4},
+		},
+	}, {
+		numbers(8, nil),
+		numbers(8, map[int]string{1: "X", 8: "Y"}),
+		2,
+		[]SideBySideLine{
+			{Left: "1", Right: "X", Type: Changed},
+			{Left: "2", Right: "2", Type: NoChange},
+			{Left: "3", Right: "3", Type: NoChange},
+			{Type: Skipped, Count: 2},
+			{Left: "6", Right: "6", Type: NoChange},
+			{Left: "7", Right: "7", Type: NoChange},
+			{Left: "8", Right: "Y", Type: Changed},
+		},
+	}, {
+		// Gaps no longer than twice the context are kept.
+		numbers(5, nil),
+		numbers(5, map[int]string{1: "X", 5: "Y"}),
+		2,
+		[]SideBySideLine{
+			{Left: "1", Right: "X", Type: Changed},
+			{Left: "2", Right: "2", Type: NoChange},
+			{Left: "3", Right: "3", Type: NoChange},
+			{Left: "4", Right: "4", Type: NoChange},
+			{Left: "5", Right: "Y", Type: Changed},
+		},
+	}}
+	for i, test := range tests {
+		lines := SideBySideContext(test.a, test.b, test.context)
+		if !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.lines, lines)
+		}
+	}
+}
+
+func TestSideBySideMoves(t *testing.T) {
+	a := []string{"f", "{", "x", "}", "a", "b"}
+	b := []string{"a", "b", "f", "{", "x", "}"}
+	want := []SideBySideLine{
+		{Right: "a", Type: Moved, MoveID: 1},
+		{Right: "b", Type: Moved, MoveID: 1},
+		{Left: "f", Right: "f", Type: NoChange},
+		{Left: "{", Right: "{", Type: NoChange},
+		{Left: "x", Right: "x", Type: NoChange},
+		{Left: "}", Right: "}", Type: NoChange},
+		{Left: "a", Type: Moved, MoveID: 1},
+		{Left: "b", Type: Moved, MoveID: 1},
+	}
+	if lines := SideBySideMoves(a, b); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+
+	// Runs that differ or are blank are not moves.
+	for _, b := range [][]string{
+		{"a", "f", "{", "x", "}"},
+		{"", "f", "{", "x", "}", "", "a", "b"},
+	} {
+		a := []string{"f", "{", "x", "}", "a", "b", ""}
+		want := SideBySide(a, b)
+		if lines := SideBySideMoves(a, b); !reflect.DeepEqual(lines, want) {
+			t.Errorf("want %v\nhave %v", want, lines)
+		}
+	}
+}
+
+func TestReverseLines(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"x", "b", "d", "e"}
+	lines := SideBySide(a, b)
+	if rev := ReverseLines(lines); !reflect.DeepEqual(rev, SideBySide(b, a)) {
+		t.Errorf("want %v\nhave %v", SideBySide(b, a), rev)
+	}
+	if twice := ReverseLines(ReverseLines(lines)); !reflect.DeepEqual(twice, lines) {
+		t.Errorf("want %v\nhave %v", lines, twice)
+	}
+}
+
+func TestSideBySideFromOps(t *testing.T) {
+	for i, test := range diffTests {
+		a, b := strings.Split(test.a, ""), strings.Split(test.b, "")
+		if lines, want := SideBySideFromOps(a, b, EditScript(lineData{a, b})), SideBySide(a, b); !reflect.DeepEqual(lines, want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, want, lines)
+		}
+		if lines, want := SideBySideFromOps(b, a, Reverse(EditScript(lineData{a, b}))), SideBySide(b, a); !reflect.DeepEqual(lines, want) {
+			t.Errorf("test %d reversed:\nwant %v\nhave %v\n", i, want, lines)
+		}
+	}
+
+	// Runs of changes made of several ops are paired as a whole.
+	a := []string{"a", "b", "c"}
+	b := []string{"x", "y", "c"}
+	ops := []Op{{Deleted, 0, 0, 1, nil}, {Added, 1, 0, 1, nil}, {Deleted, 1, 1, 1, nil}, {Added, 2, 1, 1, nil}, {NoChange, 2, 2, 1, nil}}
+	want := []SideBySideLine{{Left: "a", Right: "x", Type: Changed}, {Left: "b", Right: "y", Type: Changed}, {Left: "c", Right: "c", Type: NoChange}}
+	if lines := SideBySideFromOps(a, b, ops); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+}
+
+func TestSideBySideOrigins(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "y", "d", "e"}
+	origins := SideBySideOrigins(a, b)
+	want := [][2]int{{0, 0}, {1, 1}, {2, 2}, {3, 3}, {-1, 4}}
+	if len(origins) != len(want) {
+		t.Fatalf("want %d lines, have %d", len(want), len(origins))
+	}
+	lines := SideBySide(a, b)
+	for k, o := range origins {
+		if !reflect.DeepEqual(o.Line, lines[k]) || o.LeftIndex != want[k][0] || o.RightIndex != want[k][1] {
+			t.Errorf("line %d: want %v at %v, have %v at %d, %d", k, lines[k], want[k], o.Line, o.LeftIndex, o.RightIndex)
+		}
+	}
+
+	for i, test := range diffTests {
+		a, b := strings.Split(test.a, ""), strings.Split(test.b, "")
+		for k, o := range SideBySideOrigins(a, b) {
+			if o.LeftIndex >= 0 && a[o.LeftIndex] != o.Line.Left || o.RightIndex >= 0 && b[o.RightIndex] != o.Line.Right {
+				t.Errorf("test %d, line %d: %v does not match its origin %d, %d", i, k, o.Line, o.LeftIndex, o.RightIndex)
+			}
+			if o.LeftIndex < 0 && o.Line.Type != Added || o.RightIndex < 0 && o.Line.Type != Deleted {
+				t.Errorf("test %d, line %d: %v lacks an index", i, k, o.Line)
+			}
+		}
+	}
+}
+
+func TestSideBySideDetailed(t *testing.T) {
+	lines := SideBySideDetailed([]string{"same", "héllo", "gone"}, []string{"same", "hallo"})
+	want := []SideBySideLine{
+		{Left: "same", Right: "same", Type: NoChange},
+		{Left: "héllo", Right: "hallo", Type: Changed,
+			LeftSpans:  []Span{{0, 1, false}, {1, 3, true}, {3, 6, false}},
+			RightSpans: []Span{{0, 1, false}, {1, 2, true}, {2, 5, false}}},
+		{Left: "gone", Type: Deleted},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		x, y := randString(r, 10, "abé\xff"), randString(r, 10, "abé\xff")
+		xs, ys := spans(x, y)
+		checkSpans(t, x, xs)
+		checkSpans(t, y, ys)
+	}
+}
+
+func checkSpans(t *testing.T, s string, spans []Span) {
+	t.Helper()
+	end := 0
+	for k, sp := range spans {
+		if sp.Start != end || sp.End <= sp.Start || (k > 0 && sp.Changed == spans[k-1].Changed) {
+			t.Fatalf("%q: bad spans %v", s, spans)
+		}
+		end = sp.End
+	}
+	if end != len(s) {
+		t.Fatalf("%q: spans %v do not cover the line", s, spans)
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	lines := []SideBySideLine{
+		{Left: "\tx", Right: "\tx", Type: NoChange},
+		{Left: "ab\tc", Type: Deleted},
+		{Right: "é\t\tz", Type: Added},
+		{Left: "a\tb\n\tc", Right: "a\tb", Type: Changed,
+			LeftSpans:  []Span{{0, 3, false}, {3, 6, true}},
+			RightSpans: []Span{{0, 3, false}}},
+	}
+	want := []SideBySideLine{
+		{Left: "    x", Right: "    x", Type: NoChange},
+		{Left: "ab  c", Type: Deleted},
+		{Right: "é       z", Type: Added},
+		{Left: "a   b\n    c", Right: "a   b", Type: Changed,
+			LeftSpans:  []Span{{0, 5, false}, {5, 11, true}},
+			RightSpans: []Span{{0, 5, false}}},
+	}
+	if have := ExpandTabs(lines, 4); !reflect.DeepEqual(have, want) {
+		t.Errorf("want %v\nhave %v", want, have)
+	}
+	if lines[0].Left != "\tx" {
+		t.Errorf("ExpandTabs modified its argument")
+	}
+	if have := ExpandTabs(lines, 0); !reflect.DeepEqual(have, lines) {
+		t.Errorf("tab width 0: want %v\nhave %v", lines, have)
+	}
+}
+
+func TestBlockDiff(t *testing.T) {
+	a := "\n\nFirst paragraph,\nstill first.\n\nSecond.\n\n"
+	b := "First paragraph,\nstill first.\n\nSecond, changed.\n\nThird."
+	want := []SideBySideLine{
+		{Left: "First paragraph,\nstill first.", Right: "First paragraph,\nstill first.", Type: NoChange},
+		{Left: "Second.", Right: "Second, changed.", Type: Changed},
+		{Right: "Third.", Type: Added},
+	}
+	if lines := BlockDiff(a, b, "\n\n"); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+	if lines := BlockDiff("", "\n\n", "\n\n"); lines != nil {
+		t.Errorf("only separators: want no lines, have %v", lines)
+	}
+	want = []SideBySideLine{{Left: "a\n\nb", Right: "a\n\nc", Type: Changed}}
+	if lines := BlockDiff("a\n\nb", "a\n\nc", ""); !reflect.DeepEqual(lines, want) {
+		t.Errorf("empty separator: want %v\nhave %v", want, lines)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := []SideBySideLine{
+		{Left: "short", Right: "short", Type: NoChange},
+		{Left: "abcdefghij", Right: "abcXYZdefghijkl", Type: Changed,
+			LeftSpans:  []Span{{0, 10, false}},
+			RightSpans: []Span{{0, 3, false}, {3, 6, true}, {6, 15, false}}},
+		{Right: "ééééééé", Type: Added},
+		{Type: Skipped, Count: 3},
+	}
+	want := []SideBySideLine{
+		{Left: "short", Right: "short", Type: NoChange},
+		{Left: "abcde", Right: "abcXY", Type: Changed,
+			LeftSpans:  []Span{{0, 5, false}},
+			RightSpans: []Span{{0, 3, false}, {3, 5, true}}},
+		{Left: "fghij", Right: "Zdefg", Type: Changed, Continuation: true,
+			LeftSpans:  []Span{{0, 5, false}},
+			RightSpans: []Span{{0, 1, true}, {1, 5, false}}},
+		{Right: "hijkl", Type: Changed, Continuation: true,
+			RightSpans: []Span{{0, 5, false}}},
+		{Right: "ééééé", Type: Added},
+		{Right: "éé", Type: Added, Continuation: true},
+		{Type: Skipped, Count: 3},
+	}
+	if have := Wrap(lines, 5); !reflect.DeepEqual(have, want) {
+		t.Errorf("want %v\nhave %v", want, have)
+	}
+	if have := Wrap(lines, 0); !reflect.DeepEqual(have, lines) {
+		t.Errorf("width 0: want %v\nhave %v", lines, have)
+	}
+}
+
+func TestSideBySideIgnoreBlank(t *testing.T) {
+	a := []string{"a", "b", "", "c"}
+	b := []string{"", "a", "", "", "b", "c", " "}
+	for _, l := range SideBySideIgnoreBlank(a, b) {
+		if l.Type != NoChange {
+			t.Errorf("only blank lines added: %v", l)
+		}
+	}
+
+	a = []string{"a", "", "b", "c"}
+	b = []string{"a", "", "", "x", "c"}
+	want := []SideBySideLine{
+		{Left: "a", Right: "a", Type: NoChange},
+		{Left: "", Right: "", Type: NoChange},
+		{Left: "", Right: "", Type: NoChange},
+		{Left: "b", Right: "x", Type: Changed},
+		{Left: "c", Right: "c", Type: NoChange},
+	}
+	if lines := SideBySideIgnoreBlank(a, b); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+
+	a = []string{"a", "", "b"}
+	b = []string{"x", "", "y", "b"}
+	want = []SideBySideLine{
+		{Left: "a", Right: "x", Type: Changed},
+		{Left: "", Right: "", Type: NoChange},
+		{Right: "y", Type: Added},
+		{Left: "b", Right: "b", Type: NoChange},
+	}
+	if lines := SideBySideIgnoreBlank(a, b); !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v\nhave %v", want, lines)
+	}
+
+	// The origins tell the side of a blank line.
+	var tests = []struct {
+		a, b    []string
+		origins [][2]int
+	}{
+		{[]string{"x", ""}, []string{"x"}, [][2]int{{0, 0}, {1, -1}}},
+		{[]string{"x"}, []string{"x", ""}, [][2]int{{0, 0}, {-1, 1}}},
+		{[]string{"x", ""}, []string{"x", ""}, [][2]int{{0, 0}, {1, 1}}},
+		{[]string{"", "a", "b"}, []string{"a", "", "x"}, [][2]int{{0, -1}, {1, 0}, {-1, 1}, {2, 2}}},
+	}
+	for i, test := range tests {
+		origins := SideBySideIgnoreBlankOrigins(test.a, test.b)
+		var have [][2]int
+		for k, o := range origins {
+			have = append(have, [2]int{o.LeftIndex, o.RightIndex})
+			if o.LeftIndex >= 0 && test.a[o.LeftIndex] != o.Line.Left || o.RightIndex >= 0 && test.b[o.RightIndex] != o.Line.Right {
+				t.Errorf("test %d, line %d: %v does not match its origin %d, %d", i, k, o.Line, o.LeftIndex, o.RightIndex)
+			}
+		}
+		if !reflect.DeepEqual(have, test.origins) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.origins, have)
+		}
+	}
+}
+
+func TestCoalesceRuns(t *testing.T) {
+	lines := SideBySideOpts([]string{"a", "b", "c", "d", "e"}, []string{"a", "x", "y", "z", "d", "e"}, SeparateAddDelete)
+	want := []Block{
+		{Type: NoChange, Left: []string{"a"}, Right: []string{"a"}},
+		{Type: Deleted, Left: []string{"b", "c"}},
+		{Type: Added, Right: []string{"x", "y", "z"}},
+		{Type: NoChange, Left: []string{"d", "e"}, Right: []string{"d", "e"}},
+	}
+	if blocks := CoalesceRuns(lines); !reflect.DeepEqual(blocks, want) {
+		t.Errorf("want %v\nhave %v", want, blocks)
+	}
+	if blocks := CoalesceRuns(nil); blocks != nil {
+		t.Errorf("no lines: want no blocks, have %v", blocks)
+	}
+	// The ends of a move keep their side and MoveID.
+	lines = SideBySideMoves([]string{"m1", "m2", "n1", "a", "b"}, []string{"a", "b", "m1", "m2", "n1"})
+	want = []Block{
+		{Type: Moved, Right: []string{"a", "b"}, MoveID: 1},
+		{Type: NoChange, Left: []string{"m1", "m2", "n1"}, Right: []string{"m1", "m2", "n1"}},
+		{Type: Moved, Left: []string{"a", "b"}, MoveID: 1},
+	}
+	if blocks := CoalesceRuns(lines); !reflect.DeepEqual(blocks, want) {
+		t.Errorf("moves: want %v\nhave %v", want, blocks)
+	}
+	// Adjacent moves stay apart.
+	lines = []SideBySideLine{
+		{Left: "x", Type: Moved, MoveID: 1},
+		{Left: "y", Type: Moved, MoveID: 2},
+		{Left: "a", Right: "a", Type: NoChange},
+		{Right: "y", Type: Moved, MoveID: 2},
+		{Right: "x", Type: Moved, MoveID: 1},
+	}
+	want = []Block{
+		{Type: Moved, Left: []string{"x"}, MoveID: 1},
+		{Type: Moved, Left: []string{"y"}, MoveID: 2},
+		{Type: NoChange, Left: []string{"a"}, Right: []string{"a"}},
+		{Type: Moved, Right: []string{"y"}, MoveID: 2},
+		{Type: Moved, Right: []string{"x"}, MoveID: 1},
+	}
+	if blocks := CoalesceRuns(lines); !reflect.DeepEqual(blocks, want) {
+		t.Errorf("adjacent moves: want %v\nhave %v", want, blocks)
+	}
+
+	// Skipped lines keep their count.
+	lines = SideBySideContext(numbers(10, nil), numbers(10, map[int]string{5: "X"}), 1)
+	want = []Block{
+		{Type: Skipped, Count: 3},
+		{Type: NoChange, Left: []string{"4"}, Right: []string{"4"}},
+		{Type: Changed, Left: []string{"5"}, Right: []string{"X"}},
+		{Type: NoChange, Left: []string{"6"}, Right: []string{"6"}},
+		{Type: Skipped, Count: 4},
+	}
+	if blocks := CoalesceRuns(lines); !reflect.DeepEqual(blocks, want) {
+		t.Errorf("context: want %v\nhave %v", want, blocks)
+	}
+
+	// The blocks hold the lines of both sides in order.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := strings.Split(randString(r, 12, "abcd"), "")
+		b := strings.Split(randString(r, 12, "abcd"), "")
+		var left, right []string
+		for _, bl := range CoalesceRuns(SideBySideMoves(a, b)) {
+			left = append(left, bl.Left...)
+			right = append(right, bl.Right...)
+		}
+		if !equalLines(left, a) || !equalLines(right, b) {
+			t.Errorf("%q, %q: blocks hold %q, %q", a, b, left, right)
+		}
+	}
+}
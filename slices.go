@@ -0,0 +1,134 @@
+package diff
+
+// Generic slices
+
+// DiffSlices computes the longest common subsequence of a and b, using eq to
+// compare elements. It returns the length of the edit script like Diff and
+// calls onCommon, if not nil, the way Diff calls Interface.Common.
+func DiffSlices[T any](a, b []T, eq func(x, y T) bool, onCommon func(i, j, n int)) int {
+	return Diff(&sliceDiff[T]{a: a, b: b, eq: eq, common: onCommon})
+}
+
+// DiffComparable returns the length of the edit script needed to go from a to
+// b, comparing elements with ==.
+func DiffComparable[T comparable](a, b []T) int {
+	return DiffSlices(a, b, func(x, y T) bool { return x == y }, nil)
+}
+
+// LCS returns a longest common subsequence of a and b, using eq to compare
+// elements. The elements are taken from a.
+func LCS[T any](a, b []T, eq func(x, y T) bool) []T {
+	var lcs []T
+	DiffSlices(a, b, eq, func(i, j, n int) {
+		lcs = append(lcs, a[i:i+n]...)
+	})
+	return lcs
+}
+
+// A TokenChange is an op of the edit script between two token streams, with
+// the tokens it covers: the equal runs A of the left and B of the right
+// stream if Kind==NoChange, the tokens A deleted if Kind==Deleted, and the
+// tokens B added if Kind==Added.
+type TokenChange[T any] struct {
+	Kind int // NoChange, Added or Deleted
+	A    []T
+	B    []T
+}
+
+// TokenDiff returns the edit script from the token stream a to b, using eq to
+// compare tokens. eq may ignore parts of the tokens such as their positions,
+// in which case A and B of a NoChange hold the tokens of either stream with
+// their own positions. As with EditScript, the deletions of a run of changes
+// precede its additions.
+func TokenDiff[T any](a, b []T, eq func(x, y T) bool) []TokenChange[T] {
+	var tcs []TokenChange[T]
+	for c := range Changes(&sliceDiff[T]{a: a, b: b, eq: eq}) {
+		tc := TokenChange[T]{Kind: c.Kind}
+		if c.Kind != Added {
+			tc.A = a[c.I : c.I+c.N]
+		}
+		if c.Kind != Deleted {
+			tc.B = b[c.J : c.J+c.N]
+		}
+		tcs = append(tcs, tc)
+	}
+	return tcs
+}
+
+type sliceDiff[T any] struct {
+	a      []T
+	b      []T
+	eq     func(x, y T) bool
+	common func(i, j, n int)
+}
+
+func (d *sliceDiff[T]) Lengths() (int, int) { return len(d.a), len(d.b) }
+func (d *sliceDiff[T]) Equal(i, j int) bool { return d.eq(d.a[i], d.b[j]) }
+func (d *sliceDiff[T]) Common(i, j, n int) {
+	if d.common != nil {
+		d.common(i, j, n)
+	}
+}
+
+// DiffBytes returns the length of the edit script needed to go from a to b.
+func DiffBytes(a, b []byte) int {
+	return Diff(byteData{a, b})
+}
+
+type byteData struct {
+	a []byte
+	b []byte
+}
+
+func (d byteData) Lengths() (int, int) { return len(d.a), len(d.b) }
+func (d byteData) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d byteData) Common(i, j, n int)  {}
+
+// DiffInts returns the length of the edit script needed to go from a to b. It
+// is meant for lines that the caller has already interned into integer ids,
+// so that equal lines have equal ids.
+func DiffInts(a, b []int) int {
+	return Diff(&intDiff{a: a, b: b})
+}
+
+// SideBySideInts aligns the sequences of ids a and b like SideBySide aligns
+// lines, returning the pair of indices {i, j} into a and b shown on each row.
+// A line deleted from a has j == -1 and a line added to b has i == -1. Rows
+// with a[i] != b[j] are changed lines.
+func SideBySideInts(a, b []int) [][2]int {
+	d := &intDiff{a: a, b: b, rows: [][2]int{}}
+	Diff(d)
+	return d.rows
+}
+
+type intDiff struct {
+	a    []int
+	b    []int
+	i    int
+	j    int
+	rows [][2]int // if nil, the rows are not collected
+}
+
+func (d *intDiff) Lengths() (int, int) { return len(d.a), len(d.b) }
+func (d *intDiff) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d *intDiff) Common(i, j, n int) {
+	if d.rows == nil {
+		return
+	}
+	for d.i < i || d.j < j {
+		row := [2]int{-1, -1}
+		if d.i < i {
+			row[0] = d.i
+			d.i++
+		}
+		if d.j < j {
+			row[1] = d.j
+			d.j++
+		}
+		d.rows = append(d.rows, row)
+	}
+	for k := 0; k < n; k++ {
+		d.rows = append(d.rows, [2]int{i + k, j + k})
+	}
+	d.i, d.j = i+n, j+n
+}
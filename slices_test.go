@@ -0,0 +1,113 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSlices(t *testing.T) {
+	for i, test := range diffTests {
+		want := &stringDiff{a: test.a, b: test.b}
+		wantEdits := Diff(want)
+
+		var lcsa, lcsb []string
+		edits := DiffSlices([]byte(test.a), []byte(test.b),
+			func(x, y byte) bool { return x == y },
+			func(i, j, n int) {
+				lcsa = append(lcsa, test.a[i:i+n])
+				lcsb = append(lcsb, test.b[j:j+n])
+			})
+		if edits != wantEdits {
+			t.Errorf("test %d number of edits:\nwant %d\nhave %d\n", i, wantEdits, edits)
+		}
+		if !reflect.DeepEqual(lcsa, want.lcsa) || !reflect.DeepEqual(lcsb, want.lcsb) {
+			t.Errorf("test %d lcs:\nwant %q %q\nhave %q %q\n", i, want.lcsa, want.lcsb, lcsa, lcsb)
+		}
+		if edits := DiffComparable([]byte(test.a), []byte(test.b)); edits != wantEdits {
+			t.Errorf("test %d DiffComparable:\nwant %d\nhave %d\n", i, wantEdits, edits)
+		}
+	}
+}
+
+func TestDiffBytes(t *testing.T) {
+	for i, test := range diffTests {
+		if edits := DiffBytes([]byte(test.a), []byte(test.b)); edits != test.edits {
+			t.Errorf("test %d: want %d edits, have %d", i, test.edits, edits)
+		}
+	}
+}
+
+func TestDiffInts(t *testing.T) {
+	for i, test := range diffTests {
+		a, b := make([]int, len(test.a)), make([]int, len(test.b))
+		for k := range test.a {
+			a[k] = int(test.a[k])
+		}
+		for k := range test.b {
+			b[k] = int(test.b[k])
+		}
+		if edits := DiffInts(a, b); edits != test.edits {
+			t.Errorf("test %d: want %d edits, have %d", i, test.edits, edits)
+		}
+	}
+}
+
+func TestSideBySideInts(t *testing.T) {
+	var tests = []struct {
+		a, b []int
+		want [][2]int
+	}{
+		{nil, nil, [][2]int{}},
+		{[]int{1, 2, 3}, []int{1, 2, 3}, [][2]int{{0, 0}, {1, 1}, {2, 2}}},
+		{[]int{1, 2, 3}, []int{1, 4, 3}, [][2]int{{0, 0}, {1, 1}, {2, 2}}},
+		{[]int{1, 2, 3}, []int{1, 3}, [][2]int{{0, 0}, {1, -1}, {2, 1}}},
+		{[]int{1, 2}, []int{4, 5, 6, 2}, [][2]int{{0, 0}, {-1, 1}, {-1, 2}, {1, 3}}},
+	}
+	for i, test := range tests {
+		if have := SideBySideInts(test.a, test.b); !reflect.DeepEqual(have, test.want) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.want, have)
+		}
+	}
+}
+
+func TestLCS(t *testing.T) {
+	eq := func(x, y rune) bool { return x == y }
+	if lcs := string(LCS([]rune("abcdefghijk"), []rune("abxyzcdxyzfgxyzj"), eq)); lcs != "abcdfgj" {
+		t.Errorf("want %q, have %q", "abcdfgj", lcs)
+	}
+	for i, test := range diffTests {
+		lcs := LCS([]byte(test.a), []byte(test.b), func(x, y byte) bool { return x == y })
+		if len(test.a)+len(test.b)-2*len(lcs) != test.edits {
+			t.Errorf("test %d: LCS %q does not match %d edits", i, lcs, test.edits)
+		}
+	}
+}
+
+func TestTokenDiff(t *testing.T) {
+	type token struct {
+		text string
+		pos  int
+	}
+	lex := func(s ...string) []token {
+		var ts []token
+		pos := 0
+		for _, text := range s {
+			ts = append(ts, token{text, pos})
+			pos += len(text) + 1
+		}
+		return ts
+	}
+	a := lex("x", ":=", "f", "(", "y", ")")
+	b := lex("long", ":=", "f", "(", "z", ",", "y", ")")
+	want := []TokenChange[token]{
+		{Kind: Deleted, A: a[0:1]},
+		{Kind: Added, B: b[0:1]},
+		{Kind: NoChange, A: a[1:4], B: b[1:4]},
+		{Kind: Added, B: b[4:6]},
+		{Kind: NoChange, A: a[4:6], B: b[6:8]},
+	}
+	tcs := TokenDiff(a, b, func(x, y token) bool { return x.text == y.text })
+	if !reflect.DeepEqual(tcs, want) {
+		t.Errorf("want %v\nhave %v", want, tcs)
+	}
+}
@@ -0,0 +1,68 @@
+package diff
+
+// Stable positions
+
+// DiffStablePosition is like Diff but moves each run of changes that only
+// deletes or only adds elements as far down as possible without changing the
+// result, as git does by default. Which of several equal elements Diff
+// reports as deleted or added is not specified, but DiffStablePosition
+// always reports the last ones: for "a", "a", "a" and "a", "a", it is the
+// third "a" that is deleted. A run stops moving where it reaches the next
+// change. data.Common is called under the same contract as for Diff, and
+// the length of the edit script is that of Diff.
+//
+// Moving a run of deletions down past an unchanged element takes the element
+// of the left sequence after the run to equal the first one of the run;
+// as the Interface only compares elements of different sequences, this is
+// checked by comparing the first element of the run with the element of the
+// right sequence that the element after the run is equal to. The same holds
+// for additions the other way round, so Equal must be transitive.
+func DiffStablePosition(data Interface) int {
+	c := &collectRuns{Interface: data, rs: [][3]int{{0, 0, 0}}}
+	edits := Diff(c)
+	slideDown(data, c.rs)
+	n, m := data.Lengths()
+	r := runs{data: data}
+	for _, run := range c.rs {
+		r.add(run[0], run[1], run[2])
+	}
+	r.flush(n, m)
+	return edits
+}
+
+// slideDown moves the changes between the runs rs of a common subsequence,
+// which start with an empty run at 0, 0, down as DiffStablePosition
+// describes.
+func slideDown(data Interface, rs [][3]int) {
+	for k := 1; k < len(rs); k++ {
+		prev, next := &rs[k-1], &rs[k]
+		x0, y0 := prev[0]+prev[2], prev[1]+prev[2] // the start of the changes
+		switch {
+		case next[0] == x0 && next[1] == y0: // no changes
+		case next[1] == y0: // only deletions
+			for next[2] > 0 && data.Equal(x0, next[1]) {
+				prev[2]++
+				next[0], next[1], next[2] = next[0]+1, next[1]+1, next[2]-1
+				x0++
+			}
+		case next[0] == x0: // only additions
+			for next[2] > 0 && data.Equal(next[0], y0) {
+				prev[2]++
+				next[0], next[1], next[2] = next[0]+1, next[1]+1, next[2]-1
+				y0++
+			}
+		}
+	}
+}
+
+// collectRuns records the parts of the common subsequence Diff reports.
+type collectRuns struct {
+	Interface
+	rs [][3]int
+}
+
+func (c *collectRuns) Common(i, j, n int) {
+	if n > 0 {
+		c.rs = append(c.rs, [3]int{i, j, n})
+	}
+}
@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffStablePosition(t *testing.T) {
+	testDiff(t, DiffStablePosition)
+
+	var tests = []struct {
+		a, b   string
+		common [][3]int
+	}{
+		{"aaa", "aa", [][3]int{{0, 0, 2}, {3, 2, 0}}},
+		{"aa", "aaa", [][3]int{{0, 0, 2}, {2, 3, 0}}},
+		{"xaab", "xab", [][3]int{{0, 0, 2}, {3, 2, 1}}},
+		{"xab", "xaab", [][3]int{{0, 0, 2}, {2, 3, 1}}},
+	}
+	for i, test := range tests {
+		d := &lineDiff{a: strings.Split(test.a, ""), b: strings.Split(test.b, "")}
+		DiffStablePosition(d)
+		if !reflect.DeepEqual(d.common, test.common) {
+			t.Errorf("test %d:\nwant %v\nhave %v\n", i, test.common, d.common)
+		}
+	}
+
+	// Changes placed at the top of a run are moved to its bottom.
+	d := &lineDiff{a: strings.Split("xaaab", ""), b: strings.Split("xab", "")}
+	rs := [][3]int{{0, 0, 0}, {0, 0, 1}, {3, 1, 2}}
+	slideDown(d, rs)
+	if want := [][3]int{{0, 0, 0}, {0, 0, 2}, {4, 2, 1}}; !reflect.DeepEqual(rs, want) {
+		t.Errorf("want %v\nhave %v", want, rs)
+	}
+	d = &lineDiff{a: strings.Split("xab", ""), b: strings.Split("xaaab", "")}
+	rs = [][3]int{{0, 0, 0}, {0, 0, 1}, {1, 3, 2}}
+	slideDown(d, rs)
+	if want := [][3]int{{0, 0, 0}, {0, 0, 2}, {2, 4, 1}}; !reflect.DeepEqual(rs, want) {
+		t.Errorf("want %v\nhave %v", want, rs)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 20, "ab"), "")
+		b := strings.Split(randString(r, 20, "ab"), "")
+		d := &lineDiff{a: a, b: b}
+		checkCommon(t, d, DiffStablePosition(d))
+	}
+}
@@ -0,0 +1,181 @@
+package diff
+
+import (
+	"errors"
+	"math"
+)
+
+// Measures
+
+// Ratio returns a measure of the similarity of the two sequences of data
+// between 0 (nothing in common) and 1 (equal): 2*M/T, where M is the length of
+// the longest common subsequence and T the total number of elements in both
+// sequences. Two empty sequences are equal. This is the definition used by
+// Python's difflib.SequenceMatcher.ratio, though SequenceMatcher does not
+// look for the longest common subsequence and may find fewer matches.
+// data.Common is not called.
+func Ratio(data Interface) float64 {
+	n, m := data.Lengths()
+	if n+m == 0 {
+		return 1
+	}
+	return 2 * float64(LCSLen(data)) / float64(n+m)
+}
+
+// LCSLen returns the length of the longest common subsequence of the two
+// sequences of data. For sequences of lengths n and m, the length of the edit
+// script Diff returns is n+m-2*LCSLen(data). data.Common is not called.
+func LCSLen(data Interface) int {
+	n, m := data.Lengths()
+	return (n + m - DiffDistance(data)) / 2
+}
+
+// Equal reports whether the lines a and b are the same, that is, whether
+// the edit script Diff finds between them is empty. It returns false on
+// different lengths without comparing any lines.
+func Equal(a, b []string) bool {
+	return EqualFunc(a, b, func(x, y string) bool { return x == y })
+}
+
+// EqualFunc is like Equal but compares lines with eq, such as EqualFold.
+func EqualFunc(a, b []string, eq func(x, y string) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !eq(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffMetered is like Diff but also returns the number of times it called
+// data.Equal.
+func DiffMetered(data Interface) (edits, equalCalls int) {
+	d := &meter{Interface: data, max: math.MaxInt}
+	edits = Diff(d)
+	return edits, d.calls
+}
+
+// ErrTooManyCompares is returned by DiffMaxCompares if it gives up.
+var ErrTooManyCompares = errors.New("diff: too many comparisons")
+
+// DiffMaxCompares is like Diff but gives up and returns ErrTooManyCompares
+// instead of calling data.Equal more than max times, in which case
+// data.Common is not called. A max of zero or less allows no comparisons.
+func DiffMaxCompares(data Interface, max int) (edits int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errMaxCompares {
+				panic(r)
+			}
+			edits, err = 0, ErrTooManyCompares
+		}
+	}()
+	return Diff(&meter{Interface: data, max: max}), nil
+}
+
+// errMaxCompares is the panic with which meter stops Diff.
+var errMaxCompares = errors.New("diff: maximum number of comparisons reached")
+
+// meter counts the calls to Equal, panicking with errMaxCompares instead of
+// making more than max, or any if max is negative.
+type meter struct {
+	Interface
+	calls int
+	max   int
+}
+
+func (d *meter) Equal(i, j int) bool {
+	if d.calls >= d.max {
+		panic(errMaxCompares)
+	}
+	d.calls++
+	return d.Interface.Equal(i, j)
+}
+
+// Stats computes the numbers of elements added to, deleted from and common to
+// both sequences of data. added+deleted is the length of the edit script Diff
+// returns. data.Common is not called.
+func Stats(data Interface) (added, deleted, common int) {
+	d := &stats{Interface: data}
+	Diff(d)
+	return d.added, d.deleted, d.common
+}
+
+type stats struct {
+	Interface
+	i, j                   int
+	added, deleted, common int
+}
+
+func (d *stats) Common(i, j, n int) {
+	d.deleted += i - d.i
+	d.added += j - d.j
+	d.common += n
+	d.i, d.j = i+n, j+n
+}
+
+// LongestCommonRun returns the longest of the runs left[i:i+n] ==
+// right[j:j+n] that make up the common subsequence Diff finds, the first one
+// if there are several, or 0, 0, 0 if the sequences have nothing in common.
+// A longer run of equal elements may exist that is not part of that
+// subsequence. data.Common is not called.
+func LongestCommonRun(data Interface) (i, j, n int) {
+	d := &longestRun{Interface: data}
+	Diff(d)
+	return d.i, d.j, d.n
+}
+
+type longestRun struct {
+	Interface
+	i, j, n int
+}
+
+func (d *longestRun) Common(i, j, n int) {
+	if n > d.n {
+		d.i, d.j, d.n = i, j, n
+	}
+}
+
+// SideBySideStats counts the lines of a side-by-side diff by their type. The
+// unchanged lines include NearMatch lines and those left out in Skipped
+// lines, and the two ends of a move, which are of equal length, count as
+// deleted and added lines, so that added + deleted + 2*changed is the length
+// of the edit script.
+func SideBySideStats(lines []SideBySideLine) (added, deleted, changed, unchanged int) {
+	moved := 0
+	for _, l := range lines {
+		switch l.Type {
+		case NoChange, NearMatch:
+			unchanged++
+		case Skipped:
+			unchanged += l.Count
+		case Added:
+			added++
+		case Deleted:
+			deleted++
+		case Changed:
+			changed++
+		case Moved:
+			moved++
+		}
+	}
+	return added + moved/2, deleted + moved/2, changed, unchanged
+}
+
+// DiffVisualCost returns the number of rows of the side-by-side diff of a and b
+// that show a change, that is, the lines of SideBySide that are not NoChange.
+// Unlike the length of the edit script Diff returns, which counts a Changed
+// line as a deletion and an addition, it counts each Changed line once, so
+// that it equals the number of rows a review highlights.
+func DiffVisualCost(a, b []string) int {
+	cost := 0
+	for _, l := range SideBySide(a, b) {
+		if l.Type != NoChange {
+			cost++
+		}
+	}
+	return cost
+}
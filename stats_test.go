@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRatio(t *testing.T) {
+	var tests = []struct {
+		a     string
+		b     string
+		ratio float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "", 0},
+		{"abc", "xyz", 0},
+		{"abcd", "bcde", 0.75},
+		{"abcdefghijk", "abxyzcdxyzfgxyzj", 14.0 / 27},
+	}
+	for i, test := range tests {
+		if ratio := Ratio(&stringDiff{a: test.a, b: test.b}); ratio != test.ratio {
+			t.Errorf("test %d: want %v, have %v", i, test.ratio, ratio)
+		}
+	}
+}
+
+func TestLCSLen(t *testing.T) {
+	for i, test := range diffTests {
+		d := &stringDiff{a: test.a, b: test.b}
+		if n := LCSLen(d); test.edits != len(test.a)+len(test.b)-2*n {
+			t.Errorf("test %d: LCS of length %d does not match %d edits", i, n, test.edits)
+		}
+		if d.lcsa != nil {
+			t.Errorf("test %d: Common called", i)
+		}
+	}
+}
+
+func TestLongestCommonRun(t *testing.T) {
+	var tests = []struct {
+		a, b    string
+		i, j, n int
+	}{
+		{"", "", 0, 0, 0},
+		{"abc", "xyz", 0, 0, 0},
+		{"abc", "abc", 0, 0, 3},
+		{"abxcdey", "abzcdew", 3, 3, 3},
+		{"abxcd", "abycd", 0, 0, 2},
+	}
+	for k, test := range tests {
+		d := &stringDiff{a: test.a, b: test.b}
+		if i, j, n := LongestCommonRun(d); i != test.i || j != test.j || n != test.n {
+			t.Errorf("test %d: want %d, %d, %d, have %d, %d, %d", k, test.i, test.j, test.n, i, j, n)
+		}
+		if d.lcsa != nil {
+			t.Errorf("test %d: Common called", k)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	var tests = []struct {
+		a, b  []string
+		equal bool
+		fold  bool
+	}{
+		{nil, nil, true, true},
+		{nil, []string{""}, false, false},
+		{[]string{"a", "b"}, []string{"a", "b"}, true, true},
+		{[]string{"a", "b"}, []string{"a", "B"}, false, true},
+		{[]string{"a", "b"}, []string{"b", "a"}, false, false},
+		{[]string{"a", "b"}, []string{"a", "b", "b"}, false, false},
+	}
+	for i, test := range tests {
+		if equal := Equal(test.a, test.b); equal != test.equal {
+			t.Errorf("test %d: want %v, have %v", i, test.equal, equal)
+		}
+		if fold := EqualFunc(test.a, test.b, EqualFold); fold != test.fold {
+			t.Errorf("test %d fold: want %v, have %v", i, test.fold, fold)
+		}
+		if want := DiffDistance(lineData{test.a, test.b}) == 0; test.equal != want {
+			t.Errorf("test %d: Equal disagrees with DiffDistance", i)
+		}
+	}
+
+	called := false
+	EqualFunc([]string{"a"}, []string{"a", "b"}, func(x, y string) bool { called = true; return true })
+	if called {
+		t.Error("eq called for sequences of different lengths")
+	}
+}
+
+func TestDiffMetered(t *testing.T) {
+	for i, test := range diffTests {
+		d := &stringDiff{a: test.a, b: test.b}
+		edits, calls := DiffMetered(d)
+		if edits != test.edits {
+			t.Errorf("test %d: want %d edits, have %d", i, test.edits, edits)
+		}
+		if _, err := DiffMaxCompares(&stringDiff{a: test.a, b: test.b}, calls); err != nil {
+			t.Errorf("test %d: %d comparisons: %v", i, calls, err)
+		}
+		if calls == 0 {
+			continue
+		}
+		d = &stringDiff{a: test.a, b: test.b}
+		if _, err := DiffMaxCompares(d, calls-1); err != ErrTooManyCompares {
+			t.Errorf("test %d: %d comparisons: want %v, have %v", i, calls-1, ErrTooManyCompares, err)
+		}
+		if d.lcsa != nil {
+			t.Errorf("test %d: Common called", i)
+		}
+	}
+	if _, calls := DiffMetered(&stringDiff{a: "abc", b: "abc"}); calls != 3 {
+		t.Errorf("equal strings: want 3 comparisons, have %d", calls)
+	}
+
+	// No comparisons are allowed for a max of zero or less.
+	for _, max := range []int{0, -1, -100} {
+		d := &stringDiff{a: "abc", b: "abd"}
+		if _, err := DiffMaxCompares(d, max); err != ErrTooManyCompares {
+			t.Errorf("max %d: want %v, have %v", max, ErrTooManyCompares, err)
+		}
+		if d.lcsa != nil {
+			t.Errorf("max %d: Common called", max)
+		}
+		if edits, err := DiffMaxCompares(&stringDiff{a: "abc"}, max); edits != 3 || err != nil {
+			t.Errorf("max %d, empty right: want 3 edits, have %d, %v", max, edits, err)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	for i, test := range diffTests {
+		added, deleted, common := Stats(&stringDiff{a: test.a, b: test.b})
+		if added+deleted != test.edits || common+deleted != len(test.a) || common+added != len(test.b) {
+			t.Errorf("test %d: added %d, deleted %d, common %d do not match %d edits", i, added, deleted, common, test.edits)
+		}
+	}
+}
+
+func TestSideBySideStats(t *testing.T) {
+	var tests = []struct {
+		lines                              []SideBySideLine
+		added, deleted, changed, unchanged int
+	}{
+		{SideBySide([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"}), 1, 0, 1, 2},
+		{SideBySideContext(numbers(10, nil), numbers(10, map[int]string{5: "X"}), 1), 0, 0, 1, 9},
+		{SideBySideMoves([]string{"f", "{", "x", "}", "a", "b"}, []string{"a", "b", "f", "{", "x", "}"}), 2, 2, 0, 4},
+	}
+	for i, test := range tests {
+		added, deleted, changed, unchanged := SideBySideStats(test.lines)
+		if added != test.added || deleted != test.deleted || changed != test.changed || unchanged != test.unchanged {
+			t.Errorf("test %d: want %d, %d, %d, %d, have %d, %d, %d, %d", i,
+				test.added, test.deleted, test.changed, test.unchanged, added, deleted, changed, unchanged)
+		}
+	}
+}
+
+func TestDiffVisualCost(t *testing.T) {
+	var tests = []struct {
+		a, b        string
+		cost, edits int
+	}{
+		{"", "", 0, 0},
+		{"abc", "abc", 0, 0},
+		{"abc", "axc", 1, 2},
+		{"abc", "axcd", 2, 3},
+		{"ab", "", 2, 2},
+		{"abcd", "xy", 4, 6},
+	}
+	for i, test := range tests {
+		a, b := strings.Split(test.a, ""), strings.Split(test.b, "")
+		if cost := DiffVisualCost(a, b); cost != test.cost {
+			t.Errorf("test %d: want %d, have %d", i, test.cost, cost)
+		}
+		if edits := Diff(&lineDiff{a: a, b: b}); edits != test.edits {
+			t.Errorf("test %d: want %d edits, have %d", i, test.edits, edits)
+		}
+	}
+}
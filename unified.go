@@ -0,0 +1,293 @@
+package diff
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+)
+
+// Unified diff
+
+// Unified returns the hunks of a unified diff from a to b, with context
+// unchanged lines around each change. Changes separated by no more than
+// 2*context unchanged lines share a hunk. Unified returns the empty string if
+// a and b are equal.
+//
+// The output depends on nothing but a, b and context: it is the same byte for
+// byte on every run, on every platform and with every Go version, so it can
+// be compared to golden files in tests. A difference from a golden diff can
+// itself be shown by diffing the lines of the two diffs.
+func Unified(a, b []string, context int) string {
+	var w strings.Builder
+	writeHunks(&w, Hunks(a, b, context))
+	return w.String()
+}
+
+// UnifiedNamed is like Unified but precedes the hunks with the "---" and
+// "+++" header lines naming the old and new file.
+func UnifiedNamed(oldName, newName string, a, b []string, context int) string {
+	hs := Hunks(a, b, context)
+	if len(hs) == 0 {
+		return ""
+	}
+	var w strings.Builder
+	fmt.Fprintf(&w, "--- %s\n+++ %s\n", oldName, newName)
+	writeHunks(&w, hs)
+	return w.String()
+}
+
+// GitUnified is like UnifiedNamed but writes the header git writes, so that
+// the result can be fed to git apply. The names are paths relative to the
+// top of the repository, without the "a/" and "b/" prefixes. An empty
+// oldName means that the file is new, an empty newName that it has been
+// deleted, and different names that it has been renamed. GitUnified returns
+// the empty string if there is nothing to apply.
+func GitUnified(oldName, newName string, a, b []string, context int) string {
+	hs := Hunks(a, b, context)
+	var w strings.Builder
+	oldPath, newPath := "a/"+oldName, "b/"+newName
+	switch {
+	case oldName == "" && newName == "":
+		return ""
+	case oldName == "":
+		oldPath = "/dev/null"
+		fmt.Fprintf(&w, "diff --git a/%s b/%s\nnew file mode 100644\n", newName, newName)
+		fmt.Fprintf(&w, "index 0000000..%s\n", blobID(b))
+	case newName == "":
+		newPath = "/dev/null"
+		fmt.Fprintf(&w, "diff --git a/%s b/%s\ndeleted file mode 100644\n", oldName, oldName)
+		fmt.Fprintf(&w, "index %s..0000000\n", blobID(a))
+	case oldName != newName:
+		fmt.Fprintf(&w, "diff --git a/%s b/%s\nrename from %s\nrename to %s\n", oldName, newName, oldName, newName)
+	case len(hs) == 0:
+		return ""
+	default:
+		fmt.Fprintf(&w, "diff --git a/%s b/%s\n", oldName, newName)
+	}
+	if len(hs) == 0 {
+		return w.String()
+	}
+	if oldName != "" && newName != "" {
+		fmt.Fprintf(&w, "index %s..%s 100644\n", blobID(a), blobID(b))
+	}
+	fmt.Fprintf(&w, "--- %s\n+++ %s\n", oldPath, newPath)
+	writeHunks(&w, hs)
+	return w.String()
+}
+
+// blobID returns the abbreviated hash git gives a file consisting of lines.
+func blobID(lines []string) string {
+	size := 0
+	for _, l := range lines {
+		size += len(l) + 1
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", size)
+	for _, l := range lines {
+		fmt.Fprintf(h, "%s\n", l)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:7]
+}
+
+// Text is the content of a file as lines without their "\n", and whether the
+// last line is followed by one.
+type Text struct {
+	Lines        []string
+	FinalNewline bool
+}
+
+// UnifiedText is like Unified but tells lines that end in "\n" from those
+// that do not, marking a last line without "\n" with a "\ No newline at end
+// of file" line as diff does.
+func UnifiedText(old, new Text, context int) string {
+	var w strings.Builder
+	writeHunksEnded(&w, Hunks(old.ended(), new.ended(), context), true)
+	return w.String()
+}
+
+// DiffString returns the unified diff from a to b, which are split into lines
+// after each "\n", with 3 lines of context, as UnifiedText does. A last line
+// without "\n" is marked as such, and DiffString returns the empty string if
+// a and b are equal.
+func DiffString(a, b string) string {
+	return UnifiedText(stringText(a), stringText(b), 3)
+}
+
+// stringText returns the Text of s.
+func stringText(s string) Text {
+	lines := strings.Split(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		return Text{lines[:last], true}
+	}
+	return Text{lines, false}
+}
+
+// ended returns the lines of t with their "\n".
+func (t Text) ended() []string {
+	lines := make([]string, len(t.Lines))
+	for k, l := range t.Lines {
+		lines[k] = l
+		if k < len(t.Lines)-1 || t.FinalNewline {
+			lines[k] += "\n"
+		}
+	}
+	return lines
+}
+
+// A Hunk is a group of changed lines and their context in a unified diff.
+// Starts are line numbers as printed in the hunk header: 1-based, or for an
+// empty range the number of the line before it. Section, if not empty, is
+// printed after the hunk header, as git does with the name of the function a
+// hunk is in.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []SideBySideLine
+	Section            string
+}
+
+// Hunks returns the hunks Unified prints for the diff from a to b, with
+// context unchanged lines around each change.
+func Hunks(a, b []string, context int) []Hunk {
+	return hunks(SideBySide(a, b), context)
+}
+
+// HunksSections is like Hunks but sets the Section of each hunk to the last
+// line of a before the hunk for which section returns true, such as one that
+// starts a function. Hunks without such a line before them have no Section.
+func HunksSections(a, b []string, context int, section func(line string) bool) []Hunk {
+	hs := Hunks(a, b, context)
+	i, last := 0, ""
+	for k := range hs {
+		before := hs[k].OldStart // the number of lines of a before the hunk
+		if hs[k].OldLines > 0 {
+			before--
+		}
+		for ; i < before; i++ {
+			if section(a[i]) {
+				last = a[i]
+			}
+		}
+		hs[k].Section = last
+	}
+	return hs
+}
+
+// UnifiedSections is like Unified but follows the header of each hunk with
+// its section as found by HunksSections, for example
+// "@@ -10,7 +10,8 @@ func f() {".
+func UnifiedSections(a, b []string, context int, section func(line string) bool) string {
+	var w strings.Builder
+	writeHunks(&w, HunksSections(a, b, context, section))
+	return w.String()
+}
+
+// hunks groups the changes in lines into hunks with context unchanged lines
+// around them.
+func hunks(lines []SideBySideLine, context int) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+	var hs []Hunk
+	i, j := 0, 0 // line numbers in left and right before lines[r]
+	for r := 0; r < len(lines); {
+		if lines[r].Type == NoChange {
+			i, j = i+1, j+1
+			r++
+			continue
+		}
+		start := r - context
+		if start < 0 {
+			start = 0
+		}
+		end := r
+		for {
+			for end < len(lines) && lines[end].Type != NoChange {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].Type == NoChange {
+				next++
+			}
+			if next == len(lines) || next-end > 2*context {
+				end = min(end+context, next)
+				break
+			}
+			end = next
+		}
+		h := Hunk{OldStart: i - (r - start), NewStart: j - (r - start), Lines: lines[start:end]}
+		for _, l := range h.Lines {
+			if l.Type != Added {
+				h.OldLines++
+			}
+			if l.Type != Deleted {
+				h.NewLines++
+			}
+		}
+		i, j = h.OldStart+h.OldLines, h.NewStart+h.NewLines
+		if h.OldLines > 0 {
+			h.OldStart++
+		}
+		if h.NewLines > 0 {
+			h.NewStart++
+		}
+		hs = append(hs, h)
+		r = end
+	}
+	return hs
+}
+
+func writeHunks(w *strings.Builder, hs []Hunk) {
+	writeHunksEnded(w, hs, false)
+}
+
+// writeHunksEnded is like writeHunks but, if ended, expects each line to end
+// in "\n" and marks those that do not.
+func writeHunksEnded(w *strings.Builder, hs []Hunk, ended bool) {
+	line := func(prefix, text string) {
+		switch {
+		case !ended:
+			fmt.Fprintf(w, "%s%s\n", prefix, text)
+		case strings.HasSuffix(text, "\n"):
+			fmt.Fprintf(w, "%s%s", prefix, text)
+		default:
+			fmt.Fprintf(w, "%s%s\n\\ No newline at end of file\n", prefix, text)
+		}
+	}
+	for _, h := range hs {
+		fmt.Fprintf(w, "@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+		if h.Section != "" {
+			fmt.Fprintf(w, " %s", h.Section)
+		}
+		w.WriteString("\n")
+		for r := 0; r < len(h.Lines); {
+			if h.Lines[r].Type == NoChange {
+				line(" ", h.Lines[r].Left)
+				r++
+				continue
+			}
+			end := r
+			for end < len(h.Lines) && h.Lines[end].Type != NoChange {
+				end++
+			}
+			for _, l := range h.Lines[r:end] {
+				if l.Type != Added {
+					line("-", l.Left)
+				}
+			}
+			for _, l := range h.Lines[r:end] {
+				if l.Type != Deleted {
+					line("+", l.Right)
+				}
+			}
+			r = end
+		}
+	}
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
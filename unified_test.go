@@ -0,0 +1,236 @@
+package diff
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// numbers returns the lines "1" to "n", with line k replaced by subst[k].
+func numbers(n int, subst map[int]string) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i + 1)
+		if s, ok := subst[i+1]; ok {
+			lines[i] = s
+		}
+	}
+	return lines
+}
+
+func TestUnified(t *testing.T) {
+	var tests = []struct {
+		a       []string
+		b       []string
+		context int
+		want    string
+	}{{
+		[]string{"a", "b"},
+		[]string{"a", "b"},
+		3,
+		"",
+	}, {
+		nil,
+		[]string{"a", "b"},
+		3,
+		"@@ -0,0 +1,2 @@\n+a\n+b\n",
+	}, {
+		[]string{"a", "b"},
+		nil,
+		3,
+		"@@ -1,2 +0,0 @@\n-a\n-b\n",
+	}, {
+		numbers(10, nil),
+		numbers(10, map[int]string{5: "X"}),
+		3,
+		"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+X\n 6\n 7\n 8\n",
+	}, {
+		numbers(15, nil),
+		numbers(15, map[int]string{2: "X", 12: "Y"}),
+		2,
+		"@@ -1,4 +1,4 @@\n 1\n-2\n+X\n 3\n 4\n" +
+			"@@ -10,5 +10,5 @@\n 10\n 11\n-12\n+Y\n 13\n 14\n",
+	}, {
+		numbers(10, nil),
+		numbers(10, map[int]string{2: "X", 7: "Y"}),
+		2,
+		"@@ -1,9 +1,9 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n-7\n+Y\n 8\n 9\n",
+	}, {
+		[]string{"a", "b"},
+		[]string{"a", "x", "b"},
+		0,
+		"@@ -1,0 +2 @@\n+x\n",
+	}, {
+		[]string{"a", "b", "c"},
+		[]string{"x", "y", "c", "z"},
+		0,
+		"@@ -1,2 +1,2 @@\n-a\n-b\n+x\n+y\n@@ -3,0 +4 @@\n+z\n",
+	}}
+	for i, test := range tests {
+		if have := Unified(test.a, test.b, test.context); have != test.want {
+			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.want, have)
+		}
+	}
+}
+
+// TestUnifiedStable checks that Unified gives the same output on every run,
+// and the output fixed here, so that it can be used for golden files.
+func TestUnifiedStable(t *testing.T) {
+	a := numbers(30, map[int]string{3: "x", 4: "x", 17: "", 18: ""})
+	b := numbers(30, map[int]string{4: "x", 5: "x", 17: "", 25: "y"})
+	const want = "@@ -1,8 +1,8 @@\n 1\n 2\n+3\n x\n x\n-5\n 6\n 7\n 8\n" +
+		"@@ -15,14 +15,14 @@\n 15\n 16\n \n-\n+18\n 19\n 20\n 21\n 22\n 23\n 24\n-25\n+y\n 26\n 27\n 28\n"
+	for k := 0; k < 20; k++ {
+		if have := Unified(a, b, 3); have != want {
+			t.Fatalf("run %d:\nwant %q\nhave %q", k, want, have)
+		}
+	}
+}
+
+func TestUnifiedText(t *testing.T) {
+	abc := []string{"a", "b", "c"}
+	var tests = []struct {
+		old, new Text
+		context  int
+		want     string
+	}{
+		{Text{abc, true}, Text{abc, true}, 3, ""},
+		{Text{abc, false}, Text{abc, false}, 3, ""},
+		{Text{abc, false}, Text{[]string{"a", "b", "d"}, false}, 3,
+			"@@ -1,3 +1,3 @@\n a\n b\n-c\n\\ No newline at end of file\n+d\n\\ No newline at end of file\n"},
+		{Text{abc, false}, Text{abc, true}, 3,
+			"@@ -1,3 +1,3 @@\n a\n b\n-c\n\\ No newline at end of file\n+c\n"},
+		{Text{abc, true}, Text{[]string{"a", "b", "c", "d"}, false}, 3,
+			"@@ -1,3 +1,4 @@\n a\n b\n c\n+d\n\\ No newline at end of file\n"},
+		{Text{abc, false}, Text{[]string{"x", "b", "c"}, false}, 3,
+			"@@ -1,3 +1,3 @@\n-a\n+x\n b\n c\n\\ No newline at end of file\n"},
+		{Text{abc, false}, Text{[]string{"x", "b", "c"}, false}, 0,
+			"@@ -1 +1 @@\n-a\n+x\n"},
+	}
+	for i, test := range tests {
+		if have := UnifiedText(test.old, test.new, test.context); have != test.want {
+			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.want, have)
+		}
+	}
+}
+
+func TestDiffString(t *testing.T) {
+	var tests = []struct {
+		a, b, want string
+	}{
+		{"", "", ""},
+		{"a\n", "a\n", ""},
+		{"a", "a", ""},
+		{"a\nb\n", "a\nc\n", "@@ -1,2 +1,2 @@\n a\n-b\n+c\n"},
+		{"", "a\n", "@@ -0,0 +1 @@\n+a\n"},
+		{"a", "a\n", "@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n"},
+	}
+	for i, test := range tests {
+		if have := DiffString(test.a, test.b); have != test.want {
+			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.want, have)
+		}
+	}
+}
+
+func TestHunks(t *testing.T) {
+	a := numbers(15, nil)
+	b := numbers(15, map[int]string{2: "X", 12: "Y"})
+	want := []Hunk{{
+		OldStart: 1, OldLines: 4, NewStart: 1, NewLines: 4,
+		Lines: []SideBySideLine{
+			{Left: "1", Right: "1", Type: NoChange},
+			{Left: "2", Right: "X", Type: Changed},
+			{Left: "3", Right: "3", Type: NoChange},
+			{Left: "4", Right: "4", Type: NoChange},
+		},
+	}, {
+		OldStart: 10, OldLines: 5, NewStart: 10, NewLines: 5,
+		Lines: []SideBySideLine{
+			{Left: "10", Right: "10", Type: NoChange},
+			{Left: "11", Right: "11", Type: NoChange},
+			{Left: "12", Right: "Y", Type: Changed},
+			{Left: "13", Right: "13", Type: NoChange},
+			{Left: "14", Right: "14", Type: NoChange},
+		},
+	}}
+	if hs := Hunks(a, b, 2); !reflect.DeepEqual(hs, want) {
+		t.Errorf("want %v\nhave %v", want, hs)
+	}
+	if hs := Hunks(a, a, 2); hs != nil {
+		t.Errorf("equal files: want no hunks, have %v", hs)
+	}
+}
+
+func TestUnifiedSections(t *testing.T) {
+	a := []string{"package p", "", "func f() {", "\ta", "\tb", "}", "", "func g() {", "\tc", "\td", "\te", "}"}
+	b := []string{"package p", "", "func f() {", "\ta", "\tB", "}", "", "func g() {", "\tc", "\td", "\tE", "}"}
+	isFunc := regexp.MustCompile(`^func `).MatchString
+	const want = `@@ -4,3 +4,3 @@ func f() {
+ 	a
+-	b
++	B
+ }
+@@ -10,3 +10,3 @@ func g() {
+ 	d
+-	e
++	E
+ }
+`
+	if have := UnifiedSections(a, b, 1, isFunc); have != want {
+		t.Errorf("want %q\nhave %q", want, have)
+	}
+	hs, err := ParseUnified(strings.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sections := HunksSections(a, b, 1, isFunc); len(hs) != len(sections) || !sameHunk(hs[0], sections[0]) || !sameHunk(hs[1], sections[1]) {
+		t.Errorf("parsed hunks %v\ndo not match %v", hs, sections)
+	}
+
+	// The section of a hunk is looked for before its first line.
+	hs = HunksSections([]string{"func f() {"}, []string{"func f() {", "a"}, 0, isFunc)
+	if len(hs) != 1 || hs[0].Section != "func f() {" {
+		t.Errorf("added line: want section %q, have %v", "func f() {", hs)
+	}
+	hs = HunksSections([]string{"func f() {"}, []string{"func g() {"}, 0, isFunc)
+	if len(hs) != 1 || hs[0].Section != "" {
+		t.Errorf("changed section line: want no section, have %v", hs)
+	}
+	if have := UnifiedSections(a, b, 1, func(string) bool { return false }); have != Unified(a, b, 1) {
+		t.Errorf("no sections: want %q\nhave %q", Unified(a, b, 1), have)
+	}
+}
+
+func TestUnifiedNamed(t *testing.T) {
+	want := "--- a.txt\n+++ b.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"
+	if have := UnifiedNamed("a.txt", "b.txt", []string{"a", "b"}, []string{"a", "c"}, 3); have != want {
+		t.Errorf("want %q\nhave %q", want, have)
+	}
+	if have := UnifiedNamed("a.txt", "b.txt", []string{"a"}, []string{"a"}, 3); have != "" {
+		t.Errorf("equal files: want no output, have %q", have)
+	}
+}
+
+func TestGitUnified(t *testing.T) {
+	ab, ac := []string{"a", "b"}, []string{"a", "c"}
+	var tests = []struct {
+		oldName, newName string
+		a, b             []string
+		want             string
+	}{
+		{"f", "f", ab, ac, "diff --git a/f b/f\nindex 422c2b7..0f7bc76 100644\n--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"},
+		{"f", "f", ab, ab, ""},
+		{"", "f", nil, ab, "diff --git a/f b/f\nnew file mode 100644\nindex 0000000..422c2b7\n--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"", "f", nil, nil, "diff --git a/f b/f\nnew file mode 100644\nindex 0000000..e69de29\n"},
+		{"f", "", ab, nil, "diff --git a/f b/f\ndeleted file mode 100644\nindex 422c2b7..0000000\n--- a/f\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{"f", "g", ab, ab, "diff --git a/f b/g\nrename from f\nrename to g\n"},
+		{"f", "g", ab, ac, "diff --git a/f b/g\nrename from f\nrename to g\nindex 422c2b7..0f7bc76 100644\n--- a/f\n+++ b/g\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"},
+	}
+	for i, test := range tests {
+		if have := GitUnified(test.oldName, test.newName, test.a, test.b, 3); have != test.want {
+			t.Errorf("test %d:\nwant %q\nhave %q\n", i, test.want, have)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package diff
+
+// Unique-line anchored diff
+
+// A Keyer is an Interface that can also return a key for each element, such
+// as a hash of a line, so that the elements that occur only once can be found
+// without comparing every pair of them. Equal elements must have equal keys;
+// different elements may have equal keys as well.
+type Keyer interface {
+	Interface
+	// Key returns the key of the element at index idx of the left sequence
+	// if side is 0, and of the right sequence if side is 1.
+	Key(side, idx int) uint64
+}
+
+// DiffUniqueAnchored is like Diff but first matches the elements whose keys
+// occur exactly once in each sequence, keeping the longest run of such
+// matches that is in order on both sides, and then diffs the gaps between
+// these anchors with Diff. Unlike DiffPatience, it does not look for unique
+// elements again within the gaps. If data does not implement Keyer, it is
+// diffed with Diff. data.Common is called under the same contract as for
+// Diff, and the returned length of the edit script is that of the reported
+// subsequence, which need not be the longest.
+func DiffUniqueAnchored(data Interface) int {
+	k, ok := data.(Keyer)
+	if !ok {
+		return Diff(data)
+	}
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	countA, countB := map[uint64]int{}, map[uint64]int{}
+	atB := map[uint64]int{} // index of the last element of right with each key
+	for i := 0; i < n; i++ {
+		countA[k.Key(0, i)]++
+	}
+	for j := 0; j < m; j++ {
+		key := k.Key(1, j)
+		countB[key]++
+		atB[key] = j
+	}
+	var pairs [][2]int
+	for i := 0; i < n; i++ {
+		key := k.Key(0, i)
+		if countA[key] == 1 && countB[key] == 1 && data.Equal(i, atB[key]) {
+			pairs = append(pairs, [2]int{i, atB[key]})
+		}
+	}
+
+	r := runs{data: data}
+	i, j := 0, 0
+	for _, a := range longestIncreasing(pairs) {
+		Diff(&window{data, &r, i, a[0], j, a[1]})
+		r.add(a[0], a[1], 1)
+		i, j = a[0]+1, a[1]+1
+	}
+	Diff(&window{data, &r, i, n, j, m})
+	r.flush(n, m)
+	return n + m - 2*r.total
+}
@@ -0,0 +1,46 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// keyedLines is a lineDiff whose keys are given by key.
+type keyedLines struct {
+	lineDiff
+	key func(line string) uint64
+}
+
+func (d *keyedLines) Key(side, idx int) uint64 {
+	if side == 0 {
+		return d.key(d.a[idx])
+	}
+	return d.key(d.b[idx])
+}
+
+func TestDiffUniqueAnchored(t *testing.T) {
+	testDiff(t, DiffUniqueAnchored) // without Keyer
+
+	hash := func(line string) uint64 { return HashLines([]string{line})[0] }
+	d := &keyedLines{lineDiff{a: []string{"u", "a", "a"}, b: []string{"a", "a", "u"}}, hash}
+	if edits := DiffUniqueAnchored(d); edits != 4 {
+		t.Errorf("want 4 edits, have %d", edits)
+	}
+	if want := [][3]int{{0, 2, 1}, {3, 3, 0}}; !reflect.DeepEqual(d.common, want) {
+		t.Errorf("want %v\nhave %v", want, d.common)
+	}
+
+	// Keys that collide must not make different lines match.
+	r := rand.New(rand.NewSource(1))
+	parity := func(line string) uint64 { return uint64(line[0] % 2) }
+	for i := 0; i < 1000; i++ {
+		a := strings.Split(randString(r, 20, "abcdefgh"), "")
+		b := strings.Split(randString(r, 20, "abcdefgh"), "")
+		for _, key := range []func(string) uint64{hash, parity} {
+			d := &keyedLines{lineDiff{a: a, b: b}, key}
+			checkCommon(t, &d.lineDiff, DiffUniqueAnchored(d))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package diff
+
+// Weighted diff
+
+// WeightedInterface is an Interface whose insertions and deletions have a
+// cost for each element.
+type WeightedInterface interface {
+	Interface
+	// InsertCost returns the cost of inserting right[j].
+	InsertCost(j int) float64
+	// DeleteCost returns the cost of deleting left[i].
+	DeleteCost(i int) float64
+}
+
+// DiffWeighted returns the minimum total cost of the insertions and
+// deletions needed to go from the left to the right sequence of data. With
+// all costs 1, this is the length of the edit script Diff returns. As the
+// algorithm of Diff does not work with costs, DiffWeighted takes O(N*M)
+// time, though only O(M) memory. data.Common is not called.
+func DiffWeighted(data WeightedInterface) float64 {
+	n, m := data.Lengths()
+	if err := checkLengths(n, m); err != nil {
+		panic(err)
+	}
+	// cost[j] is the cost of going from left[:i] to right[:j], for the
+	// current i.
+	cost := make([]float64, m+1)
+	for j := 1; j <= m; j++ {
+		cost[j] = cost[j-1] + data.InsertCost(j-1)
+	}
+	for i := 1; i <= n; i++ {
+		diag := cost[0] // cost[j-1] for i-1
+		cost[0] += data.DeleteCost(i - 1)
+		for j := 1; j <= m; j++ {
+			c := min(cost[j]+data.DeleteCost(i-1), cost[j-1]+data.InsertCost(j-1))
+			if data.Equal(i-1, j-1) {
+				c = min(c, diag)
+			}
+			diag, cost[j] = cost[j], c
+		}
+	}
+	return cost[m]
+}
@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// weightedDiff is a stringDiff in which deleting or inserting a space costs
+// 0.25 and every other element 1.
+type weightedDiff struct {
+	stringDiff
+	unit bool // all costs are 1
+}
+
+func (d *weightedDiff) cost(c byte) float64 {
+	if c == ' ' && !d.unit {
+		return 0.25
+	}
+	return 1
+}
+
+func (d *weightedDiff) InsertCost(j int) float64 { return d.cost(d.b[j]) }
+func (d *weightedDiff) DeleteCost(i int) float64 { return d.cost(d.a[i]) }
+
+func TestDiffWeighted(t *testing.T) {
+	for i, test := range diffTests {
+		d := &weightedDiff{stringDiff: stringDiff{a: test.a, b: test.b}, unit: true}
+		if cost := DiffWeighted(d); cost != float64(test.edits) {
+			t.Errorf("test %d: want cost %d, have %v", i, test.edits, cost)
+		}
+	}
+
+	var tests = []struct {
+		a, b string
+		cost float64
+	}{
+		{"", "", 0},
+		{"a b", "ab", 0.25},
+		{"ab", " a b ", 0.75},
+		// Two spaces deleted and "x" inserted.
+		{"a b c", "abxc", 1.5},
+		{strings.Repeat(" ", 8), "", 2},
+	}
+	for i, test := range tests {
+		d := &weightedDiff{stringDiff: stringDiff{a: test.a, b: test.b}}
+		if cost := DiffWeighted(d); cost != test.cost {
+			t.Errorf("test %d: want cost %v, have %v", i, test.cost, cost)
+		}
+	}
+}
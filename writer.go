@@ -0,0 +1,39 @@
+package diff
+
+import "sync"
+
+// Incremental input
+
+// A SideBySideWriter collects the lines of two sequences one at a time and
+// diffs them when flushed, for code that produces the lines incrementally.
+// Its methods may be called from several goroutines at once, so that each
+// side can be written by its own. The zero value is an empty writer ready to
+// use.
+type SideBySideWriter struct {
+	mu   sync.Mutex
+	a, b []string
+}
+
+// WriteLeft appends line to the left sequence.
+func (w *SideBySideWriter) WriteLeft(line string) {
+	w.mu.Lock()
+	w.a = append(w.a, line)
+	w.mu.Unlock()
+}
+
+// WriteRight appends line to the right sequence.
+func (w *SideBySideWriter) WriteRight(line string) {
+	w.mu.Lock()
+	w.b = append(w.b, line)
+	w.mu.Unlock()
+}
+
+// Flush returns the side-by-side diff of the lines written so far, as
+// computed by SideBySide, and empties both sequences.
+func (w *SideBySideWriter) Flush() []SideBySideLine {
+	w.mu.Lock()
+	a, b := w.a, w.b
+	w.a, w.b = nil, nil
+	w.mu.Unlock()
+	return SideBySide(a, b)
+}
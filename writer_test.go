@@ -0,0 +1,43 @@
+package diff
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSideBySideWriter(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "c", "d", "e"}
+	var w SideBySideWriter
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for _, line := range a {
+			w.WriteLeft(line)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, line := range b {
+			w.WriteRight(line)
+		}
+	}()
+	wg.Wait()
+	if want, have := SideBySide(a, b), w.Flush(); !reflect.DeepEqual(have, want) {
+		t.Errorf("want %v\nhave %v", want, have)
+	}
+
+	// Flushing empties the writer.
+	if have := w.Flush(); len(have) != 0 {
+		t.Errorf("want no lines, have %v", have)
+	}
+	for i := 0; i < 3; i++ {
+		w.WriteRight(strconv.Itoa(i))
+	}
+	if want, have := SideBySide(nil, []string{"0", "1", "2"}), w.Flush(); !reflect.DeepEqual(have, want) {
+		t.Errorf("want %v\nhave %v", want, have)
+	}
+}